@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Records a finished task after the fact.",
+	Long: `Records a task that was not tracked with "start" and "end".
+	The language defaults to the one of the project like with "start".
+	Times like "9:15" refer to today or to the day given with --date,
+	complete times can be given as "YYYY-MM-DD HH:MM".
+	The task must not overlap any other task.`,
+	Example: `  goalkeeper add -p goalkeeper -l Go --start 9:15 --end 11:40
+  goalkeeper add -p website -l TypeScript -s 14:00 -e 15:30 --date 2024-10-01
+  goalkeeper add -p goalkeeper -l Go -s 9:00 -e 10:00 -t review -n "reviewed the sqlite store"`,
+	Args: cobra.NoArgs,
+	RunE: runAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.Flags().StringP("project", "p", "", "The name of the project of that task")
+	addCmd.Flags().StringP("language", "l", "", "The programming language of that task, defaults to the one of the project")
+	addCmd.Flags().StringP("start", "s", "", "The start time of the task")
+	addCmd.Flags().StringP("end", "e", "", "The end time of the task")
+	addCmd.Flags().StringP("date", "d", "", "The day of the task, e.g. 2024-10-01 or yesterday, defaults to today")
+	addTaskFlags(addCmd)
+
+	addCmd.MarkFlagRequired("project")
+	addCmd.MarkFlagRequired("start")
+	addCmd.MarkFlagRequired("end")
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	project, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return fmt.Errorf("error getting project value: %w", err)
+	}
+
+	language, err := taskLanguage(cmd, project)
+	if err != nil {
+		return err
+	}
+
+	day := now()
+	if cmd.Flags().Changed("date") {
+		dateStr, err := cmd.Flags().GetString("date")
+		if err != nil {
+			return fmt.Errorf("error getting date value: %w", err)
+		}
+		if day, err = pkg.ParseDate(dateStr, now()); err != nil {
+			return fmt.Errorf("--date: %w", err)
+		}
+	}
+
+	start, err := parseTimeFlag(cmd, "start", day)
+	if err != nil {
+		return err
+	}
+
+	end, err := parseTimeFlag(cmd, "end", day)
+	if err != nil {
+		return err
+	}
+
+	if end.After(now()) {
+		return fmt.Errorf("--end: %s is in the future", end.Format(pkg.DateTimeFormat))
+	}
+
+	task := pkg.NewTask(project, language, start)
+	task.FinishAt(end)
+	if err := applyTaskFlags(cmd, task); err != nil {
+		return err
+	}
+
+	if err := checkOverlap(task); err != nil {
+		return err
+	}
+
+	if err := store.Append(task); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	log.Printf(
+		"Successfully saved task %s: %s, %s - %s\n",
+		task.ID,
+		task.Label(),
+		task.Start.Format(pkg.DateTimeFormat),
+		task.End.Format(pkg.TimeFormat),
+	)
+	return nil
+}
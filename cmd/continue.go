@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+// RECENT_LIMIT is the number of project and language pairs the picker of
+// continue offers.
+const RECENT_LIMIT = 10
+
+var continueCmd = &cobra.Command{
+	Use:   "continue [N]",
+	Short: "Starts a new task like the last one.",
+	Long: `Starts a new task with the project, language, tags and note of the last task.
+	With N the Nth most recent distinct project and language pair is continued,
+	with --pick a list of the recent pairs is shown to choose from.
+	Use --at to start it earlier, e.g. "--at 9:15" or "--at -15m".`,
+	Example: `  goalkeeper continue
+  goalkeeper continue 2
+  goalkeeper continue --pick`,
+	Aliases: []string{"cont"},
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runContinue,
+}
+
+func init() {
+	rootCmd.AddCommand(continueCmd)
+
+	continueCmd.Flags().BoolP("pick", "i", false, "Pick the task to continue from the recent ones")
+	continueCmd.Flags().String("at", "", "Start the task at this time instead of now")
+	addTaskFlags(continueCmd)
+}
+
+func runContinue(cmd *cobra.Command, args []string) error {
+	if lastTask != nil && !lastTask.IsFinished() {
+		return fmt.Errorf("%s is still running, call 'end' or 'switch' first", lastTask.Label())
+	}
+
+	pick, err := cmd.Flags().GetBool("pick")
+	if err != nil {
+		return fmt.Errorf("error getting pick value: %w", err)
+	}
+
+	n := 1
+	if len(args) == 1 {
+		if pick {
+			return errors.New("either give N or use --pick")
+		}
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return fmt.Errorf("N must be a positive number, got %q", args[0])
+		}
+	}
+
+	tasks, err := loadTasks(time.Time{}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	limit := n
+	if pick {
+		limit = RECENT_LIMIT
+	}
+
+	recent := pkg.RecentTasks(tasks, limit)
+	if len(recent) == 0 {
+		return errors.New("there is no task to continue yet, call 'start' first")
+	}
+
+	if pick {
+		if n, err = pickRecent(recent, os.Stdin); err != nil {
+			return err
+		}
+	} else if n > len(recent) {
+		return fmt.Errorf("there are only %d distinct projects and languages to continue", len(recent))
+	}
+
+	start := now()
+	if cmd.Flags().Changed("at") {
+		if start, err = parseTimeFlag(cmd, "at", now()); err != nil {
+			return err
+		}
+		if start.After(now()) {
+			return fmt.Errorf("--at: %s is in the future", start.Format(pkg.DateTimeFormat))
+		}
+	}
+
+	previous := recent[n-1]
+	task := pkg.NewTask(previous.Project, previous.Language, start)
+	task.AddTags(previous.Tags...)
+	task.Note = previous.Note
+	if err := applyTaskFlags(cmd, task); err != nil {
+		return err
+	}
+
+	if err := checkOverlap(task); err != nil {
+		return err
+	}
+
+	if err := store.Append(task); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	if isStructured() {
+		return printReport(cmd, pkg.NewTaskReport(task))
+	}
+
+	log.Printf(
+		"Successfully saved task %s: %s, started at: %s\n",
+		task.ID,
+		task.Label(),
+		task.Start.Format(pkg.DateTimeFormat),
+	)
+	return nil
+}
+
+// pickRecent lists recent and asks which of them to continue. It returns the
+// chosen number, starting at 1.
+func pickRecent(recent []*pkg.Task, in io.Reader) (int, error) {
+	tab := table.NewTable(
+		table.NewHeader("#", true),
+		table.NewHeader("Project"),
+		table.NewHeader("Language", true),
+		table.NewHeader("Tags"),
+		table.NewHeader("Note"),
+		table.NewHeader("Last", true),
+	).WithRoundedCorners()
+
+	for i, t := range recent {
+		tab.AddRow([]string{
+			strconv.Itoa(i + 1),
+			t.Project,
+			t.Language,
+			pkg.FormatTags(t.Tags),
+			t.Note,
+			t.Start.In(location).Format(pkg.DateFormat),
+		})
+	}
+
+	fmt.Fprintln(os.Stderr, tab)
+	fmt.Fprintf(os.Stderr, "Continue which task? [1-%d, default 1]: ", len(recent))
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return 0, errors.New("no task picked")
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return 1, nil
+	}
+
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(recent) {
+		return 0, fmt.Errorf("%q is not a number from 1 to %d", line, len(recent))
+	}
+	return n, nil
+}
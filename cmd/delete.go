@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Removes a recorded task.",
+	Long: `Removes the task with the given ID. The IDs are shown by "status".
+	A deleted task can be brought back with "undo".`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runDelete,
+	Aliases: []string{"rm"},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	task, err := store.Get(args[0])
+	if errors.Is(err, pkg.ErrTaskNotFound) {
+		return fmt.Errorf("there is no task with ID %q", args[0])
+	}
+	if err := checkLoad(err); err != nil {
+		return err
+	}
+
+	if err := store.Delete(task); err != nil {
+		return fmt.Errorf("error deleting task: %w", err)
+	}
+
+	fmt.Printf("Deleted task %s: %s\n", task.ID, describeTask(task))
+	return nil
+}
+
+// describeTask returns a short description of task for messages.
+func describeTask(task *pkg.Task) string {
+	description := fmt.Sprintf("%s %s - %s",
+		task.Label(),
+		task.Start.Format(pkg.DateTimeFormat),
+		pkg.FormatTimeOrTBD(task.End, pkg.TimeFormat),
+	)
+	if task.IsPaused() {
+		description += ", paused"
+	}
+	return description
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks the task file for malformed rows.",
+	Long: `Lists all rows of the task file that can not be read.
+	Those rows are skipped by every other command.
+	Use "--fix" to move them to the quarantine file, where they can be repaired by hand.`,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix", false, "Move malformed rows to the quarantine file")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	fix, err := cmd.Flags().GetBool("fix")
+	if err != nil {
+		return fmt.Errorf("error getting fix value: %w", err)
+	}
+
+	if tomlConfig.ConfigSection.Backend == pkg.BACKEND_SQLITE {
+		fmt.Println("The sqlite backend has no malformed rows, nothing to check")
+		return nil
+	}
+
+	filename := tomlConfig.ConfigSection.Filename
+
+	var skipped *pkg.SkippedRowsError
+	if fix {
+		skipped, err = pkg.QuarantineRows(filename, location)
+	} else {
+		_, err = pkg.LoadTasks(filename, location)
+		if errors.As(err, &skipped) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if skipped == nil {
+		fmt.Printf("%s is healthy\n", filename)
+		return nil
+	}
+
+	tab := table.NewTable(
+		table.NewHeader("Line", true),
+		table.NewHeader("Field", true),
+		table.NewHeader("Problem"),
+	).WithRoundedCorners().WithTitle(filename)
+
+	for _, row := range skipped.Rows {
+		tab.AddRow([]string{fmt.Sprint(row.Line), row.Field, fmt.Sprintf("%q: %v", row.Value, row.Err)})
+	}
+
+	fmt.Println(tab)
+
+	if fix {
+		fmt.Printf("Moved %d rows to %s\n", len(skipped.Rows), pkg.DEFAULT_QUARANTINE_NAME)
+	} else {
+		fmt.Println("Run 'goalkeeper doctor --fix' to move them to", pkg.DEFAULT_QUARANTINE_NAME)
+	}
+
+	return nil
+}
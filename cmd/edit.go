@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Changes a recorded task.",
+	Long: `Changes the project, language, start or end time, tags or note of the task with the given ID.
+	--tag replaces all tags of the task, --tag "" removes them.
+	The IDs are shown by "status".
+	Times can be given as "HH:MM" (on the day of the task), "YYYY-MM-DD HH:MM",
+	a day and a time like "yesterday 14:30" or an offset like "+15m".`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	editCmd.Flags().StringP("project", "p", "", "The new project of the task")
+	editCmd.Flags().StringP("language", "l", "", "The new language of the task")
+	editCmd.Flags().StringP("start", "s", "", "The new start time of the task")
+	editCmd.Flags().StringP("end", "e", "", "The new end time of the task")
+	editCmd.Flags().StringArrayP("tag", "t", nil, "The new tags of the task, can be repeated")
+	editCmd.Flags().StringP("note", "n", "", "The new note of the task")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	task, err := store.Get(args[0])
+	if errors.Is(err, pkg.ErrTaskNotFound) {
+		return fmt.Errorf("there is no task with ID %q", args[0])
+	}
+	if err := checkLoad(err); err != nil {
+		return err
+	}
+
+	edited := *task
+	flags := cmd.Flags()
+
+	if flags.Changed("project") {
+		if edited.Project, err = flags.GetString("project"); err != nil {
+			return fmt.Errorf("error getting project value: %w", err)
+		}
+	}
+
+	if flags.Changed("language") {
+		if edited.Language, err = flags.GetString("language"); err != nil {
+			return fmt.Errorf("error getting language value: %w", err)
+		}
+	}
+
+	if flags.Changed("start") {
+		if edited.Start, err = parseTimeFlag(cmd, "start", task.Start); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("end") {
+		if edited.End, err = parseTimeFlag(cmd, "end", task.Start); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("tag") {
+		tags, err := flags.GetStringArray("tag")
+		if err != nil {
+			return fmt.Errorf("error getting tag value: %w", err)
+		}
+		edited.Tags = pkg.ParseTags(tags...)
+	}
+
+	if flags.Changed("note") {
+		if edited.Note, err = flags.GetString("note"); err != nil {
+			return fmt.Errorf("error getting note value: %w", err)
+		}
+	}
+
+	if edited.Equal(*task) {
+		fmt.Fprintln(os.Stderr, "Nothing to change, use --project, --language, --start, --end, --tag or --note")
+		return nil
+	}
+
+	if err := checkOverlap(&edited); err != nil {
+		return err
+	}
+
+	if err := store.Update(&edited); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	tasks, err := loadDay(edited.Start)
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+	printTasks(tasks, edited.Start, false)
+	return nil
+}
+
+// parseTimeFlag parses the time given in flag name. Times of day refer to the
+// day of ref.
+func parseTimeFlag(cmd *cobra.Command, name string, ref time.Time) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting %s value: %w", name, err)
+	}
+
+	t, err := pkg.ParseTime(value, ref)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// checkOverlap makes sure task ends after it starts and does not share time
+// with any other stored task. Stored tasks are replaced by the version in
+// pending that has the same ID, for changes that are saved together.
+func checkOverlap(task *pkg.Task, pending ...*pkg.Task) error {
+	if err := task.Validate(); err != nil {
+		return err
+	}
+
+	end := task.End
+	if !task.IsFinished() {
+		end = now()
+	}
+
+	tasks, err := loadTasks(time.Time{}, end)
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	for i, t := range tasks {
+		for _, p := range pending {
+			if t.ID == p.ID {
+				tasks[i] = p
+			}
+		}
+	}
+
+	return pkg.CheckOverlap(task, tasks)
+}
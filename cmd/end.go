@@ -1,8 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"time"
+	"log"
 
 	"github.com/aaronbittel/goalkeeper/pkg"
 	"github.com/spf13/cobra"
@@ -13,25 +14,84 @@ var endCmd = &cobra.Command{
 	Use:   "end",
 	Short: "Ends a running task.",
 	Long: `Sets the end time for the currently running task and ends it.
+	Use --at to end it earlier, e.g. "--at 17:30" or "--at -10m".
+	--tag adds tags to the task and --note replaces its note.
 	Now you can begin a new task with "start"`,
-	Run:     runEnd,
+	RunE:    runEnd,
 	Aliases: []string{"stop"},
 }
 
-func runEnd(cmd *cobra.Command, args []string) {
-	if len(tasks) == 0 || lastTask.IsFinished() {
+func runEnd(cmd *cobra.Command, args []string) error {
+	if lastTask == nil || lastTask.IsFinished() {
+		if isStructured() {
+			return errors.New("no task is running, call 'start' first")
+		}
 		fmt.Println("First call 'start' to begin a new task")
-		return
+		return nil
 	}
 
-	lastTask := tasks[len(tasks)-1]
-	lastTask.Finish()
-	pkg.SaveTasks(tomlConfig.ConfigSection.Filename, tasks)
+	if cmd.Flags().Changed("at") {
+		at, err := parseTimeFlag(cmd, "at", now())
+		if err != nil {
+			return err
+		}
+		if at.After(now()) {
+			return fmt.Errorf("--at: %s is in the future", at.Format(pkg.DateTimeFormat))
+		}
+		lastTask.FinishAt(at)
+	} else {
+		lastTask.Finish()
+	}
+
+	if err := checkEnded(lastTask); err != nil {
+		return err
+	}
+
+	if err := applyTaskFlags(cmd, lastTask); err != nil {
+		return err
+	}
+
+	if err := checkOverlap(lastTask); err != nil {
+		return err
+	}
+
+	if err := store.Update(lastTask); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
 
-	tasksToday := pkg.GetTasksForDate(tasks, time.Now())
-	printTasks(tasksToday, time.Now(), false)
+	if isStructured() {
+		return printReport(cmd, pkg.NewTaskReport(lastTask))
+	}
+
+	log.Printf(
+		"Successfully ended task %s: %s, ended at: %s\n",
+		lastTask.ID,
+		lastTask.Label(),
+		lastTask.End.Format(pkg.DateTimeFormat),
+	)
+
+	tasksToday, err := loadDay(now())
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+	printTasks(tasksToday, now(), false)
+	return nil
+}
+
+// checkEnded makes sure task ends after it starts. Times are kept in whole
+// seconds, so a task ended in the second it started has no time and is
+// refused instead of being saved or dropped.
+func checkEnded(task *pkg.Task) error {
+	if !task.End.After(task.Start) {
+		return fmt.Errorf("%s started at %s and can only end after that, remove it with 'delete %s' instead",
+			task.Label(), task.Start.Format(pkg.DateTimeFormat), task.ID)
+	}
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(endCmd)
+
+	endCmd.Flags().String("at", "", "End the task at this time instead of now")
+	addTaskFlags(endCmd)
 }
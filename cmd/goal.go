@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "Shows the goals and how they changed over time.",
+	Long: `Shows the daily, weekly, monthly and yearly goals in force today and every
+	change recorded in goals.history of config.toml.
+	Use "goal set" to change a goal without affecting how past days are judged.`,
+	Args: cobra.NoArgs,
+	RunE: runGoal,
+}
+
+var goalSetCmd = &cobra.Command{
+	Use:   "set <daily|weekly|monthly|yearly> <duration>",
+	Short: "Changes a goal from today (or --from) on.",
+	Long: `Records a new goal in goals.history of config.toml. Days before the change
+	keep being judged against the goal that was in force back then.
+	The duration is given in minutes like "90" or as "1h30m".`,
+	Example: `  goalkeeper goal set daily 2h
+  goalkeeper goal set weekly 600 --from 2024-10-07`,
+	Args: cobra.ExactArgs(2),
+	RunE: runGoalSet,
+}
+
+func init() {
+	rootCmd.AddCommand(goalCmd)
+	goalCmd.AddCommand(goalSetCmd)
+
+	goalSetCmd.Flags().String("from", "", "The first day of the new goal, defaults to today")
+}
+
+func runGoal(cmd *cobra.Command, args []string) error {
+	goals := tomlConfig.GoalsSection
+	current := goals.At(now())
+
+	tab := table.NewTable(
+		table.NewHeader("From"),
+		table.NewHeader("Daily", true),
+		table.NewHeader("Weekly", true),
+		table.NewHeader("Monthly", true),
+		table.NewHeader("Yearly", true),
+	).WithRoundedCorners()
+
+	tab.AddRow([]string{"start", formatGoal(goals.Daily), formatGoal(goals.Weekly),
+		formatGoal(goals.Monthly), formatGoal(goals.Yearly)})
+
+	for _, c := range goals.History {
+		tab.AddRow([]string{c.From, formatGoalChange(c.Daily), formatGoalChange(c.Weekly),
+			formatGoalChange(c.Monthly), formatGoalChange(c.Yearly)})
+	}
+
+	tab.AddSeperator()
+	tab.AddRow([]string{"today", formatGoal(current.Daily), formatGoal(current.Weekly),
+		formatGoal(current.Monthly), formatGoal(current.Yearly)})
+
+	fmt.Println(tab)
+	return nil
+}
+
+func runGoalSet(cmd *cobra.Command, args []string) error {
+	minutes, err := parseMinutes(args[1])
+	if err != nil {
+		return err
+	}
+
+	day := pkg.StartOfDay(now())
+	if cmd.Flags().Changed("from") {
+		value, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return fmt.Errorf("error getting from value: %w", err)
+		}
+		if day, err = pkg.ParseDate(value, now()); err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+	}
+
+	// change the file as it is, not the defaults used for a broken config
+	if _, err := pkg.LoadTomlConfig(); err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	if err := pkg.SaveGoal(args[0], minutes, day); err != nil {
+		return err
+	}
+
+	fmt.Printf("The %s goal is %s from %s on\n", args[0], formatGoal(minutes), day.Format(pkg.DateFormat))
+	return nil
+}
+
+// parseMinutes parses a goal given in minutes like "90" or as a duration
+// like "1h30m".
+func parseMinutes(value string) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 {
+			return 0, errors.New("a goal must not be negative")
+		}
+		return n, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("could not parse goal %q, use minutes like 90 or a duration like 1h30m", value)
+	}
+	return int(d.Minutes()), nil
+}
+
+func formatGoal(minutes int) string {
+	if minutes == 0 {
+		return "-"
+	}
+	return formatDuration(time.Duration(minutes) * time.Minute)
+}
+
+func formatGoalChange(minutes *int) string {
+	if minutes == nil {
+		return ""
+	}
+	return formatGoal(*minutes)
+}
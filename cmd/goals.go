@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var goalsCmd = &cobra.Command{
+	Use:   "goals",
+	Short: "Shows the progress towards the milestones.",
+	Long: `Shows every milestone of goals.milestones in config.toml: the time spent so far,
+	the percentage done, the average pace per week since the first task and
+	the day the milestone is reached at that pace. Milestones with a deadline
+	also show the time per day needed to make it.`,
+	Args: cobra.NoArgs,
+	RunE: runGoals,
+}
+
+func init() {
+	rootCmd.AddCommand(goalsCmd)
+}
+
+func runGoals(cmd *cobra.Command, args []string) error {
+	milestones := tomlConfig.GoalsSection.Milestones
+	if len(milestones) == 0 {
+		fmt.Fprintln(os.Stderr, `There are no milestones yet, add one to config.toml:
+
+[[goals.milestones]]
+name = "100 hours of Rust"
+language = "Rust"
+hours = 100
+deadline = "2025-06-30"`)
+		return nil
+	}
+
+	tasks, err := loadTasks(time.Time{}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	tab := table.NewTable(
+		table.NewHeader("Milestone"),
+		table.NewHeader("Progress"),
+		table.NewHeader("Done", true),
+		table.NewHeader("Target", true),
+		table.NewHeader("Pace/week", true),
+		table.NewHeader("Projected", true),
+		table.NewHeader("Deadline", true),
+		table.NewHeader("Needed/day", true),
+	).WithRoundedCorners()
+
+	for _, m := range milestones {
+		p := pkg.NewMilestoneProgress(m, tasks, now())
+
+		projected := "-"
+		switch {
+		case p.Remaining() == 0:
+			projected = "reached"
+		case !p.Projected.IsZero():
+			projected = p.Projected.Format(pkg.DateFormat)
+		}
+
+		deadline, needed := "-", "-"
+		if !p.Deadline.IsZero() {
+			deadline = p.Deadline.AddDate(0, 0, -1).Format(pkg.DateFormat)
+			if !p.OnTrack() {
+				deadline += " (behind)"
+			}
+			if n := p.Needed(now()); n > 0 {
+				needed = formatDuration(n)
+			}
+		}
+
+		tab.AddRow([]string{
+			m.Name,
+			fmt.Sprintf("%s %3d%%", table.ProgressBar(p.Ratio(), 20), int(p.Ratio()*100)),
+			formatDuration(p.Done),
+			formatDuration(m.Target()),
+			formatDuration((7 * p.Pace).Round(time.Minute)),
+			projected,
+			deadline,
+			needed,
+		})
+	}
+
+	fmt.Println(tab)
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "Shows the tracked time of every day as a calendar grid.",
+	Long: `Shows a grid with a column for every week and a row for every weekday,
+	shaded by the time tracked on each day compared with the daily goal in force
+	on it. Days without a goal are compared with two hours.
+	Without --year the last 53 weeks are shown. A year takes 53 columns, or 54
+	for a leap year that begins on the last day of a week.`,
+	Example: `  goalkeeper heatmap
+  goalkeeper heatmap --year 2025 --project goalkeeper`,
+	Args: cobra.NoArgs,
+	RunE: runHeatmap,
+}
+
+func init() {
+	rootCmd.AddCommand(heatmapCmd)
+
+	heatmapCmd.Flags().IntP("year", "y", 0, "Show this calendar year")
+	heatmapCmd.Flags().StringP("project", "p", "", "Only count the tasks of this project")
+	heatmapCmd.Flags().StringP("language", "l", "", "Only count the tasks of this language")
+}
+
+func runHeatmap(cmd *cobra.Command, args []string) error {
+	year, err := cmd.Flags().GetInt("year")
+	if err != nil {
+		return fmt.Errorf("error getting year value: %w", err)
+	}
+
+	project, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return fmt.Errorf("error getting project value: %w", err)
+	}
+
+	language, err := cmd.Flags().GetString("language")
+	if err != nil {
+		return fmt.Errorf("error getting language value: %w", err)
+	}
+
+	today := pkg.StartOfDay(now())
+
+	// shown are the days of period, the grid starts on first
+	period := pkg.Period{From: calendar.Week(today).Shift(-52).From, To: today.AddDate(0, 0, 1)}
+	if year != 0 {
+		period = pkg.Year(time.Date(year, time.January, 1, 0, 0, 0, 0, location))
+	}
+	first := calendar.Week(period.From).From
+	weeks := pkg.HeatmapWeeks(first, period.LastDay())
+
+	tasks, err := store.Query(pkg.TaskFilter{
+		Project:  project,
+		Language: language,
+		From:     period.From,
+		To:       period.To,
+	})
+	if err := checkLoad(err); err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+	totals := pkg.DailyTotals(tasks, location)
+
+	rowLabels := make([]string, 7)
+	for i := range rowLabels {
+		rowLabels[i] = first.AddDate(0, 0, i).Weekday().String()[:2]
+	}
+
+	heatmap := table.NewHeatmap(rowLabels, weeks)
+	out := cmd.OutOrStdout()
+	if useColors(out) {
+		heatmap.WithColors()
+	}
+
+	months := map[int]string{}
+	monthTotals := map[int]string{}
+	var (
+		total      time.Duration
+		activeDays int
+	)
+
+	for day := period.From; day.Before(period.To) && !day.After(today); day = day.AddDate(0, 0, 1) {
+		row, col := pkg.HeatmapCell(first, day)
+
+		heatmap.Set(row, col, pkg.HeatLevel(totals[day], dailyGoal(day)))
+
+		if day.Day() == 1 || day.Equal(period.From) {
+			month := pkg.Month(day)
+			var sum time.Duration
+			for d, t := range totals {
+				if month.Contains(d) {
+					sum += t
+				}
+			}
+			// the month is labeled in its first full week, which can be past
+			// the end of the grid
+			if day.Weekday() != calendar.WeekStart {
+				col++
+			}
+			if col < weeks {
+				months[col] = day.Format("Jan")
+				monthTotals[col] = fmt.Sprintf("%dh", int(sum.Hours()))
+			}
+		}
+
+		total += totals[day]
+		if totals[day] > 0 {
+			activeDays++
+		}
+	}
+
+	heatmap.AddTopLine(months)
+	heatmap.AddTopLine(monthTotals)
+
+	fmt.Fprint(out, heatmap)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, heatmap.Legend([]string{"nothing", "< half the goal", "< goal", "goal", "1.5x goal"}))
+	fmt.Fprintf(out, "%s tracked on %d days from %s\n", formatDuration(total), activeDays, period)
+	return nil
+}
+
+// useColors reports whether out is a terminal that should get colors.
+func useColors(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pauses the running task.",
+	Long: `Starts a break in the running task, the break does not count towards its duration.
+	Use --at to start the break earlier, e.g. "--at 12:00" or "--at -10m".
+	Continue the task with "resume", "end" also ends the break.`,
+	Args: cobra.NoArgs,
+	RunE: runPause,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resumes the paused task.",
+	Long: `Ends the break of the paused task.
+	Use --at to end the break earlier, e.g. "--at 12:45" or "--at -5m".`,
+	Args: cobra.NoArgs,
+	RunE: runResume,
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+
+	pauseCmd.Flags().String("at", "", "Start the break at this time instead of now")
+	resumeCmd.Flags().String("at", "", "End the break at this time instead of now")
+}
+
+func runPause(cmd *cobra.Command, args []string) error {
+	if lastTask == nil || lastTask.IsFinished() {
+		return errors.New("no task is running, call 'start' first")
+	}
+
+	at, err := breakTime(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := lastTask.Pause(at); err != nil {
+		if errors.Is(err, pkg.ErrAlreadyPaused) {
+			return fmt.Errorf("%s is already paused since %s, call 'resume' first", lastTask.Project,
+				lastTask.Pauses[len(lastTask.Pauses)-1].Start.Format(pkg.TimeFormat))
+		}
+		return err
+	}
+
+	if err := store.Update(lastTask); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	log.Printf("Paused %s at %s\n", lastTask.Label(), at.Format(pkg.TimeFormat))
+	return nil
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	if lastTask == nil || !lastTask.IsPaused() {
+		return errors.New("no task is paused")
+	}
+
+	at, err := breakTime(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := lastTask.Resume(at); err != nil {
+		return err
+	}
+
+	if err := store.Update(lastTask); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	pause := lastTask.Pauses[len(lastTask.Pauses)-1]
+	log.Printf("Resumed %s after a break of %s\n", lastTask.Label(), formatDuration(pause.Duration()))
+	return nil
+}
+
+// breakTime returns the time given with --at, otherwise now.
+func breakTime(cmd *cobra.Command) (time.Time, error) {
+	if !cmd.Flags().Changed("at") {
+		return now(), nil
+	}
+
+	at, err := parseTimeFlag(cmd, "at", now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if at.After(now()) {
+		return time.Time{}, fmt.Errorf("--at: %s is in the future", at.Format(pkg.DateTimeFormat))
+	}
+	return at, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Rolls the task file back to a backup.",
+	Long: `Every save keeps a copy of the previous task file in the backups directory.
+	Without arguments the available backups are listed, newest first.
+	Pass the number or the name of a backup to restore it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	if tomlConfig.ConfigSection.Backend == pkg.BACKEND_SQLITE {
+		fmt.Fprintln(os.Stderr, "Backups are only kept for the csv backend")
+		return nil
+	}
+
+	filename := tomlConfig.ConfigSection.Filename
+
+	backups, err := pkg.ListBackups(filename)
+	if err != nil {
+		return fmt.Errorf("error listing backups: %w", err)
+	}
+
+	if len(backups) == 0 {
+		fmt.Fprintf(os.Stderr, "There are no backups of %s yet\n", filename)
+		return nil
+	}
+
+	if len(args) == 0 {
+		printBackups(backups)
+		return nil
+	}
+
+	backup, ok := findBackup(backups, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown backup: %s\n", args[0])
+		printBackups(backups)
+		return nil
+	}
+
+	if err := pkg.RestoreBackup(filename, backup); err != nil {
+		return fmt.Errorf("error restoring %s: %w", backup.Name, err)
+	}
+
+	fmt.Printf("Restored %s from %s\n", filename, backup.Name)
+	return nil
+}
+
+func findBackup(backups []pkg.Backup, arg string) (pkg.Backup, bool) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(backups) {
+			return pkg.Backup{}, false
+		}
+		return backups[n-1], true
+	}
+
+	for _, b := range backups {
+		if b.Name == arg {
+			return b, true
+		}
+	}
+
+	return pkg.Backup{}, false
+}
+
+func printBackups(backups []pkg.Backup) {
+	tab := table.NewTable(
+		table.NewHeader("#", true),
+		table.NewHeader("Backup"),
+		table.NewHeader("Created", true),
+	).WithRoundedCorners()
+
+	for i, b := range backups {
+		tab.AddRow([]string{strconv.Itoa(i + 1), b.Name, b.Created.Format(pkg.DateTimeFormat)})
+	}
+
+	fmt.Println(tab)
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/aaronbittel/goalkeeper/pkg"
 	"github.com/spf13/cobra"
@@ -11,8 +15,13 @@ import (
 
 var (
 	tomlConfig pkg.TomlDocument
-	tasks      []*pkg.Task
+	store      *pkg.JournaledStore
 	lastTask   *pkg.Task
+	location   *time.Location
+	calendar   pkg.Calendar
+	output     string
+
+	skippedWarned bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -20,46 +29,163 @@ var rootCmd = &cobra.Command{
 	Use:   "goalkeeper",
 	Short: "A Cli tool for keeping track of progress.",
 	Long: `To keep track for your programming journey progress.
-	Set a goal and keep track your time spent on projects and programming languages.`,
-	PersistentPreRun: rootPreRun,
+	Set a goal and keep track your time spent on projects and programming languages.
+
+	With --output json, yaml or csv the commands status, summary, start and
+	end print data for scripts instead of tables. The keys do not change:
+	  start, end: a task with id, project, language, start, end (null while
+	    running), running, paused, duration_seconds, break_seconds, breaks
+	    (each with start and end), tags and note
+	  status:     date, tasks, total_seconds, break_seconds and goal, the daily
+	    goal with goal, from, to, target_seconds, done_seconds,
+	    remaining_seconds, progress and work_days_left
+	  summary:    from, to, tasks, days, projects, languages and tags (each
+	    with name, duration_seconds and share), total_seconds and goals
+	As csv, status prints the tasks and summary prints kind, name,
+	duration_seconds and share rows for every day, project, language, tag
+	and the total.`,
+	PersistentPreRunE:  rootPreRun,
+	PersistentPostRunE: rootPostRun,
+	SilenceErrors:      true,
+	SilenceUsage:       true,
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", friendlyError(err))
 		os.Exit(1)
 	}
 }
 
-func rootPreRun(cmd *cobra.Command, args []string) {
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", pkg.OUTPUT_TABLE,
+		"Output format of status, summary, start and end: "+strings.Join(pkg.OutputFormats, ", "))
+}
+
+func rootPreRun(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(pkg.OutputFormats, output) {
+		return fmt.Errorf("--output: unknown format %q, use one of %s",
+			output, strings.Join(pkg.OutputFormats, ", "))
+	}
+
 	var err error
 	tomlConfig, err = pkg.LoadTomlConfig()
 
 	if err != nil {
-		if !os.IsNotExist(err) {
-			log.Fatalf("could not parse config.toml: %v", err)
-		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			if !errors.Is(err, pkg.ErrConfigInvalid) {
+				return err
+			}
 
-		// config file does not exist -> create config file
-		pkg.CreateProjectDir(pkg.DefaultPath())
-		err := pkg.CreateTomlFile(pkg.DefaultTomlConfig())
-		if err != nil {
-			log.Fatal(err)
+			// broken config -> keep going with the defaults
+			warn("%s\nUsing the default configuration until it is fixed.", friendlyError(err))
+			tomlConfig = pkg.DefaultTomlConfig()
+		} else {
+			// config file does not exist -> create config file
+			if err := createDefaultConfig(); err != nil {
+				return err
+			}
 		}
 	}
 
-	csvFilename := tomlConfig.ConfigSection.Filename
+	location, err = tomlConfig.ConfigSection.Location()
+	if err != nil {
+		return err
+	}
 
-	tasks, err = pkg.LoadTasks(csvFilename)
+	calendar, err = tomlConfig.ConfigSection.Calendar()
 	if err != nil {
-		path := filepath.Join(pkg.DefaultPath() + csvFilename)
-		_, err = os.Create(path)
-		if err != nil {
-			log.Fatalf("error creating csv file: %v", err)
-		}
+		return err
+	}
+
+	store, err = pkg.OpenStore(tomlConfig.ConfigSection)
+	if err != nil {
+		return fmt.Errorf("error opening task storage: %w", err)
+	}
+
+	// doctor reports the skipped rows itself
+	skippedWarned = cmd == doctorCmd
+
+	// commands that need the tasks report loading problems themselves
+	lastTask, err = store.Last()
+	if checkLoad(err) != nil {
+		lastTask = nil
+	}
+
+	return nil
+}
+
+func rootPostRun(cmd *cobra.Command, args []string) error {
+	if store != nil {
+		return store.Close()
+	}
+	return nil
+}
+
+func createDefaultConfig() error {
+	path, err := pkg.DefaultPath()
+	if err != nil {
+		return err
 	}
 
-	if len(tasks) > 0 {
-		lastTask = tasks[len(tasks)-1]
+	if err := pkg.CreateProjectDir(path); err != nil {
+		return err
 	}
+
+	tomlConfig = pkg.DefaultTomlConfig()
+	return pkg.CreateTomlFile(tomlConfig)
+}
+
+// friendlyError turns errors from pkg into a message that tells the user
+// what went wrong and where to look.
+func friendlyError(err error) string {
+	var rowErr *pkg.MalformedRowError
+	switch {
+	case errors.As(err, &rowErr):
+		return fmt.Sprintf("%v\nFix or remove the row in %s to continue.",
+			err, tomlConfig.ConfigSection.Filename)
+	case errors.Is(err, pkg.ErrConfigInvalid):
+		return fmt.Sprintf("%v\nEdit %s in %s to fix it.",
+			err, pkg.DEFAULT_CONFIG_NAME, pkg.DEFAULT_PATH)
+	default:
+		return err.Error()
+	}
+}
+
+// checkLoad turns skipped malformed rows into a warning, so commands keep
+// working with the rows that could be read. All other errors are returned.
+func checkLoad(err error) error {
+	var skipped *pkg.SkippedRowsError
+	if !errors.As(err, &skipped) {
+		return err
+	}
+
+	if !skippedWarned {
+		warn("%v, run 'goalkeeper doctor' for details", skipped)
+		skippedWarned = true
+	}
+	return nil
+}
+
+// now returns the current time in the configured time zone.
+func now() time.Time {
+	return time.Now().In(location)
+}
+
+// isStructured reports whether --output asks for data instead of tables.
+func isStructured() bool {
+	return output != pkg.OUTPUT_TABLE
+}
+
+// printReport writes r to the output of cmd in the format of --output.
+func printReport(cmd *cobra.Command, r pkg.Report) error {
+	if err := pkg.WriteReport(cmd.OutOrStdout(), output, r); err != nil {
+		return fmt.Errorf("error writing %s output: %w", output, err)
+	}
+	return nil
+}
+
+func warn(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
 }
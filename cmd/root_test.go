@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aaronbittel/goalkeeper/pkg"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,5 +22,90 @@ func TestRootCmd(t *testing.T) {
 	err := statusCmd.Execute()
 	assert.NoError(t, err, "Cmd.Execute should not return an error")
 
-	t.Log("hi", tasks)
+	t.Log("hi", lastTask)
+}
+
+func TestStartJSONOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// capture everything written to stdout, including by pkg
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	assert.NoError(t, err)
+	defer stdout.Close()
+
+	orig := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() {
+		os.Stdout = orig
+		output = pkg.OUTPUT_TABLE
+		rootCmd.SetArgs(nil)
+	})
+
+	rootCmd.SetArgs([]string{"start", "-p", "goalkeeper", "-l", "Go", "-o", pkg.OUTPUT_JSON})
+	err = rootCmd.Execute()
+	os.Stdout = orig
+	assert.NoError(t, err, "start should not return an error")
+	assert.Equal(t, pkg.BACKEND_CSV, tomlConfig.ConfigSection.Backend)
+
+	data, err := os.ReadFile(stdout.Name())
+	assert.NoError(t, err)
+
+	var report pkg.TaskReport
+	assert.NoError(t, json.Unmarshal(data, &report), "stdout should only hold the json report:\n%s", data)
+	assert.Equal(t, "goalkeeper", report.Project)
+	assert.Equal(t, "Go", report.Language)
+	assert.True(t, report.Running)
+}
+
+func TestSwitchInStartSecond(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { rootCmd.SetArgs(nil) })
+
+	execute := func(args ...string) error {
+		rootCmd.SetArgs(args)
+		return rootCmd.Execute()
+	}
+
+	// --at puts all commands into the second the task started
+	at := time.Now().Add(-time.Hour).Format(pkg.DateTimeFormat)
+	assert.NoError(t, execute("start", "-p", "goalkeeper", "-l", "Go", "--at", at))
+
+	err := execute("switch", "-p", "website", "-l", "Go", "--at", at)
+	assert.ErrorContains(t, err, "can only end after that", "switch should refuse a task without time")
+
+	err = execute("end", "--at", at)
+	assert.ErrorContains(t, err, "can only end after that", "end should refuse a task without time")
+
+	s, err := pkg.OpenStore(tomlConfig.ConfigSection)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	tasks, err := s.Query(pkg.TaskFilter{})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1, "only the started task should be stored") {
+		assert.Equal(t, "goalkeeper", tasks[0].Project)
+		assert.False(t, tasks[0].IsFinished(), "the task should still be running")
+	}
+}
+
+func TestHeatmapOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	rootCmd.SetArgs([]string{"heatmap", "--year", "2024"})
+	assert.NoError(t, rootCmd.Execute())
+
+	lines := strings.Split(out.String(), "\n")
+	// two lines of labels, a row for every weekday, the legend and the total
+	if assert.GreaterOrEqual(t, len(lines), 11) {
+		assert.Contains(t, lines[0], "Jan")
+		assert.True(t, strings.HasPrefix(lines[2], "Mo"), "expected the grid to begin on Monday, got %q", lines[2])
+		assert.Contains(t, lines[len(lines)-2], "tracked on 0 days")
+	}
 }
@@ -8,50 +8,139 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	project  string
-	language string
-)
+var project string
 
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "This starts a new task.",
 	Long: `This starts a new task with for the given "Project" and "Language.
+	Without --language the default of projects.<name>.language in the config
+	is used, otherwise the language of the last task of the project.
+	Use --language "" for a task without a language.
 	The start time is set to now and the end time is TBD.
+	Use --at to start it earlier, e.g. "--at 9:15" or "--at -15m".
+	Describe it with --tag, which can be repeated, and --note.
 	Finish a task using the "end" command."`,
+	Example: `  goalkeeper start -p goalkeeper -l Go
+  goalkeeper start -p goalkeeper -l Go --tag bugfix --note "fix the csv parser"
+  goalkeeper start -p goalkeeper
+  goalkeeper start -p meeting -l ""`,
 	Aliases: []string{"begin"},
-	Run:     runStart,
+	RunE:    runStart,
 }
 
-func runStart(cmd *cobra.Command, args []string) {
-	if len(tasks) > 0 && !lastTask.IsFinished() {
+func runStart(cmd *cobra.Command, args []string) error {
+	if lastTask != nil && !lastTask.IsFinished() {
+		if isStructured() {
+			return fmt.Errorf("task %s is still running, call 'end' first", lastTask.ID)
+		}
 		fmt.Printf(
-			"First call 'end' to finish the running task:\n\t %s (%s) started at: %s\n",
-			lastTask.Project,
-			lastTask.Language,
-			lastTask.Start.Format("2006-01-02 15:04:05"),
+			"First call 'end' to finish the running task:\n\t %s started at: %s\n",
+			lastTask.Label(),
+			lastTask.Start.Format(pkg.DateTimeFormat),
 		)
-		return
+		return nil
+	}
+
+	start := now()
+	if cmd.Flags().Changed("at") {
+		at, err := parseTimeFlag(cmd, "at", now())
+		if err != nil {
+			return err
+		}
+		if at.After(now()) {
+			return fmt.Errorf("--at: %s is in the future", at.Format(pkg.DateTimeFormat))
+		}
+		start = at
+	}
+
+	language, err := taskLanguage(cmd, project)
+	if err != nil {
+		return err
+	}
+
+	task := pkg.NewTask(project, language, start)
+	if err := applyTaskFlags(cmd, task); err != nil {
+		return err
 	}
 
-	task := pkg.NewTask(project, language)
-	tasks = append(tasks, task)
-	pkg.SaveTasks(tomlConfig.ConfigSection.Filename, tasks)
+	if err := checkOverlap(task); err != nil {
+		return err
+	}
+
+	if err := store.Append(task); err != nil {
+		return fmt.Errorf("error saving task: %w", err)
+	}
+
+	if isStructured() {
+		return printReport(cmd, pkg.NewTaskReport(task))
+	}
 
 	log.Printf(
-		"Successfully saved task %s (%s), started at: %s\n",
-		task.Project,
-		task.Language,
-		task.Start.Format("2006-01-02 15:04:05"),
+		"Successfully saved task %s: %s, started at: %s\n",
+		task.ID,
+		task.Label(),
+		task.Start.Format(pkg.DateTimeFormat),
 	)
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
 
 	startCmd.Flags().StringVarP(&project, "project", "p", "", "The name of the project of that task")
-	startCmd.Flags().StringVarP(&language, "language", "l", "", "The programming language of that task")
+	startCmd.Flags().StringP("language", "l", "", "The programming language of that task, defaults to the one of the project")
+
+	startCmd.Flags().String("at", "", "Start the task at this time instead of now")
+	addTaskFlags(startCmd)
 
 	startCmd.MarkFlagRequired("project")
-	startCmd.MarkFlagRequired("language")
+}
+
+// addTaskFlags adds --tag and --note to a command that records tasks.
+func addTaskFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("tag", "t", nil, "Tag the task, e.g. bugfix or #learning, can be repeated")
+	cmd.Flags().StringP("note", "n", "", "A note about what was done")
+}
+
+// applyTaskFlags adds the tags of --tag to task and replaces its note with
+// --note, if it was given. New tasks also get the default tags of their
+// project.
+func applyTaskFlags(cmd *cobra.Command, task *pkg.Task) error {
+	if task.ID == "" {
+		task.AddTags(pkg.ParseTags(tomlConfig.Projects[task.Project].Tags...)...)
+	}
+
+	tags, err := cmd.Flags().GetStringArray("tag")
+	if err != nil {
+		return fmt.Errorf("error getting tag value: %w", err)
+	}
+	task.AddTags(pkg.ParseTags(tags...)...)
+
+	if cmd.Flags().Changed("note") {
+		if task.Note, err = cmd.Flags().GetString("note"); err != nil {
+			return fmt.Errorf("error getting note value: %w", err)
+		}
+	}
+	return nil
+}
+
+// taskLanguage returns the language of a new task of project: the one given
+// with --language, which may be empty, the default of the project in the
+// config or the language of the last task of the project. Without any of
+// these the task has no language.
+func taskLanguage(cmd *cobra.Command, project string) (string, error) {
+	if cmd.Flags().Changed("language") {
+		language, err := cmd.Flags().GetString("language")
+		if err != nil {
+			return "", fmt.Errorf("error getting language value: %w", err)
+		}
+		return language, nil
+	}
+
+	if language := tomlConfig.Projects[project].Language; language != "" {
+		return language, nil
+	}
+
+	return lastLanguage(project)
 }
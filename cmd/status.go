@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"time"
 
@@ -17,97 +16,167 @@ var statusCmd = &cobra.Command{
 	Long: `This prints the status of the current day.
 	This includes all the tasks (project, languages) as well as start and end times.
 	This also sums up all the hours and shows the progress for today.`,
-	Run: runStatus,
+	RunE: runStatus,
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
-	statusCmd.Flags().StringP("date", "d", "", "Retrieve the status of a specific day in the past")
+	statusCmd.Flags().StringP("date", "d", "", "Retrieve the status of a specific day in the past, e.g. 2024-10-01, yesterday or last friday")
 	statusCmd.Flags().BoolP("yesterday", "y", false, "Retrieve yesterday's status")
 	statusCmd.Flags().BoolP("percentage", "p", false, "Show the progress in percentage")
 }
 
-func runStatus(cmd *cobra.Command, args []string) {
+func runStatus(cmd *cobra.Command, args []string) error {
 	dateStr, err := cmd.Flags().GetString("date")
 	if err != nil {
-		log.Fatalf("[status] error getting date value: %v", err)
+		return fmt.Errorf("error getting date value: %w", err)
 	}
 
 	isYesterday, err := cmd.Flags().GetBool("yesterday")
 	if err != nil {
-		log.Fatalf("[status] error getting yesterday value: %v", err)
+		return fmt.Errorf("error getting yesterday value: %w", err)
 	}
 
-	date := time.Now()
+	date := now()
 	if isYesterday {
 		date = date.AddDate(0, 0, -1)
 	}
 
 	if !isYesterday && dateStr != "" {
-		date, err = time.Parse("2.1.2006", dateStr)
+		date, err = pkg.ParseDate(dateStr, now())
 		if err != nil {
-			fmt.Fprintf(os.Stderr,
-				"could not parse date: %s, please use format 'DD.MM.YYYY'\n",
-				dateStr)
+			return fmt.Errorf("--date: %w", err)
 		}
 
-		if date.After(time.Now()) {
+		if date.After(now()) {
 			fmt.Fprintf(os.Stderr,
 				"Time travel hasn't been invented yet\n%s is in the future\n",
-				date.Format("2.1.2006"))
-			return
+				date.Format(pkg.DateFormat))
+			return nil
 		}
 	}
 
 	showPercentage, err := cmd.Flags().GetBool("percentage")
 	if err != nil {
-		log.Fatalf("[status] error getting percentage value: %v", err)
+		return fmt.Errorf("error getting percentage value: %w", err)
+	}
+
+	tasks, err := loadDay(date)
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	if isStructured() {
+		return printDayReport(cmd, tasks, date)
 	}
 
-	tasks := pkg.GetTasksForDate(tasks, date)
 	if len(tasks) == 0 {
-		fmt.Fprintf(os.Stderr, "There are no tasks for that day")
-		return
+		fmt.Fprintln(os.Stderr, "There are no tasks for that day")
+		return nil
 	}
 
 	printTasks(tasks, date, showPercentage)
+	return nil
 }
 
 func printTasks(tasks []*pkg.Task, date time.Time, showPercentage bool) {
-	var totalDuration time.Duration
+	totalDuration := pkg.TotalDuration(tasks)
+	var totalBreaks time.Duration
+
+	// breaks, tags and notes only get a column when they are used
+	var hasBreaks, hasTags, hasNotes bool
+	for _, t := range tasks {
+		hasBreaks = hasBreaks || len(t.Pauses) > 0
+		hasTags = hasTags || len(t.Tags) > 0
+		hasNotes = hasNotes || t.Note != ""
+	}
 
-	tab := table.NewTable(
+	headers := []*table.Header{
+		table.NewHeader("ID", true),
 		table.NewHeader("Project").HeadingCentered(),
 		table.NewHeader("Language", true),
 		table.NewHeader("Start", true),
 		table.NewHeader("End", true),
 		table.NewHeader("Duration", true),
-	).WithRoundedCorners().WithTitle(date.Format("Mon Jan 02 '06"))
+	}
+	if hasBreaks {
+		headers = append(headers, table.NewHeader("Breaks", true))
+	}
+	if hasTags {
+		headers = append(headers, table.NewHeader("Tags"))
+	}
+	if hasNotes {
+		headers = append(headers, table.NewHeader("Note"))
+	}
+
+	tab := table.NewTable(headers...).WithRoundedCorners().WithTitle(date.Format("Mon Jan 02 '06"))
 
 	for _, t := range tasks {
-		tab.AddRow([]string{
-			t.Project, t.Language, t.Start.Format(pkg.TimeFormat),
-			pkg.FormatTimeOrTBD(t.End, pkg.TimeFormat), formatDuration(t.Duration()),
-		})
-		totalDuration += t.Duration()
+		end := pkg.FormatTimeOrTBD(t.End, pkg.TimeFormat)
+		if t.IsPaused() {
+			end = "paused"
+		}
+
+		row := []string{
+			t.ID, t.Project, t.Language, t.Start.Format(pkg.TimeFormat),
+			end, formatDuration(t.Duration()),
+		}
+		if hasBreaks {
+			row = append(row, formatDuration(t.BreakDuration()))
+		}
+		if hasTags {
+			row = append(row, pkg.FormatTags(t.Tags))
+		}
+		if hasNotes {
+			row = append(row, t.Note)
+		}
+		tab.AddRow(row)
+		totalBreaks += t.BreakDuration()
 	}
 	tab.AddSeperator()
 
 	percentage := ""
-	goalMinutes := tomlConfig.GoalsSection.Daily
+	goalMinutes := tomlConfig.GoalsSection.At(date).Daily
 	if showPercentage && goalMinutes != 0 {
 		perc := totalDuration.Minutes() / float64(goalMinutes)
 		percentage = fmt.Sprintf(" (%d%%)", int(perc*100))
 	}
 
-	tab.AddRow([]string{"", "", "", "", fmt.Sprintf(
+	total := []string{"", "", "", "", "", fmt.Sprintf(
 		"%s%s",
 		formatDuration(totalDuration),
-		percentage)})
+		percentage)}
+	if hasBreaks {
+		total = append(total, formatDuration(totalBreaks))
+	}
+	for len(total) < len(headers) {
+		total = append(total, "")
+	}
+	tab.AddRow(total)
 
 	fmt.Println(tab)
 }
 
+// printDayReport writes the tasks of the day of date and the progress towards
+// its daily goal in the format of --output.
+func printDayReport(cmd *cobra.Command, tasks []*pkg.Task, date time.Time) error {
+	goal := time.Duration(tomlConfig.GoalsSection.At(date).Daily) * time.Minute
+	return printReport(cmd, pkg.NewDayReport(pkg.StartOfDay(date), tasks, goal, calendar, now()))
+}
+
+// loadTasks returns all tasks that started in [from, to). Skipped rows are
+// only reported as a warning.
+func loadTasks(from, to time.Time) ([]*pkg.Task, error) {
+	tasks, err := store.Load(from, to)
+	return tasks, checkLoad(err)
+}
+
+// loadDay returns all tasks that started on the day of date.
+func loadDay(date time.Time) ([]*pkg.Task, error) {
+	from := pkg.StartOfDay(date)
+	return loadTasks(from, from.AddDate(0, 0, 1))
+}
+
 func formatDuration(dur time.Duration) string {
 	return fmt.Sprintf("%dh %dm", int(dur.Hours()), int(dur.Minutes())%60)
 }
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	table "github.com/aaronbittel/goalkeeper/internal"
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var streakCmd = &cobra.Command{
+	Use:   "streak",
+	Short: "Shows on how many days in a row the daily goal was met.",
+	Long: `Shows the current and the longest streak of work days on which the daily goal
+	was met, and on how many of all work days it was met.
+	Rest days (see config.work_days) never break a streak, and today only breaks
+	it once the day is over. Every day is judged against the goal that was in
+	force on it (see "goal set").`,
+	Args: cobra.NoArgs,
+	RunE: runStreak,
+}
+
+func init() {
+	rootCmd.AddCommand(streakCmd)
+
+	streakCmd.Flags().IntP("weeks", "w", 4, "Number of recent weeks to show day by day")
+}
+
+func runStreak(cmd *cobra.Command, args []string) error {
+	weeks, err := cmd.Flags().GetInt("weeks")
+	if err != nil {
+		return fmt.Errorf("error getting weeks value: %w", err)
+	}
+
+	tasks, err := loadTasks(time.Time{}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	today := pkg.StartOfDay(now())
+	streak := pkg.ComputeStreak(tasks, calendar, dailyGoal, today)
+
+	if streak.Judged == 0 && dailyGoal(today) == 0 {
+		fmt.Fprintln(os.Stderr, "There is no daily goal, set one with 'goalkeeper goal set daily 2h'")
+		return nil
+	}
+
+	tab := table.NewTable(
+		table.NewHeader("Streak"),
+		table.NewHeader("Days", true),
+		table.NewHeader("Period"),
+	).WithRoundedCorners()
+
+	current := "-"
+	if streak.Current > 0 {
+		current = streak.CurrentStart.Format(pkg.DateFormat)
+	}
+	longest := "-"
+	if streak.Longest > 0 {
+		longest = fmt.Sprintf("%s - %s",
+			streak.LongestStart.Format(pkg.DateFormat), streak.LongestEnd.Format(pkg.DateFormat))
+	}
+
+	tab.AddRow([]string{"Current", fmt.Sprint(streak.Current), current})
+	tab.AddRow([]string{"Longest", fmt.Sprint(streak.Longest), longest})
+	tab.AddSeperator()
+	tab.AddRow([]string{
+		"Completion",
+		fmt.Sprintf("%d/%d", streak.Met, streak.Judged),
+		fmt.Sprintf("%d%%", int(streak.Rate()*100)),
+	})
+
+	fmt.Println(tab)
+
+	if weeks > 0 {
+		printStreakDays(streak, today, weeks)
+	}
+	return nil
+}
+
+// dailyGoal returns the daily goal in force on day.
+func dailyGoal(day time.Time) time.Duration {
+	return time.Duration(tomlConfig.GoalsSection.At(day).Daily) * time.Minute
+}
+
+// streakSymbols are shown for every day of the recent weeks.
+var streakSymbols = map[pkg.DayStatus]string{
+	pkg.DayRest:   "·",
+	pkg.DayMet:    "■",
+	pkg.DayMissed: "□",
+	pkg.DayOpen:   "?",
+}
+
+// printStreakDays shows the status of every day of the last weeks, one week
+// per line.
+func printStreakDays(streak pkg.Streak, today time.Time, weeks int) {
+	first := calendar.Week(today).Shift(-(weeks - 1)).From
+
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", len(pkg.DateFormat)+1))
+	for i := range 7 {
+		day := first.AddDate(0, 0, i)
+		header.WriteString(" " + day.Weekday().String()[:2])
+	}
+	fmt.Println(header.String())
+
+	for week := first; !week.After(today); week = week.AddDate(0, 0, 7) {
+		var line strings.Builder
+		line.WriteString(week.Format(pkg.DateFormat) + " ")
+
+		for i := range 7 {
+			day := week.AddDate(0, 0, i)
+			symbol := " "
+			if !day.After(today) {
+				symbol = streakSymbols[streak.Days[day]]
+			}
+			line.WriteString("  " + symbol)
+		}
+		fmt.Println(line.String())
+	}
+
+	fmt.Printf("\n%s met  %s missed  %s rest day  %s today\n",
+		streakSymbols[pkg.DayMet], streakSymbols[pkg.DayMissed],
+		streakSymbols[pkg.DayRest], streakSymbols[pkg.DayOpen])
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"slices"
 	"sort"
+	"strconv"
 	"time"
 
 	table "github.com/aaronbittel/goalkeeper/internal"
@@ -12,70 +11,420 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	projectSummary  bool
-	languageSummary bool
+const (
+	// CHART_WIDTH is the length of the longest bar of a chart.
+	CHART_WIDTH = 30
+	// TIMELINE_HEIGHT is the height of the chart of daily totals.
+	TIMELINE_HEIGHT = 8
+	// TIMELINE_MAX_COLUMNS is the most days drawn as bars, longer periods
+	// are drawn as a sparkline.
+	TIMELINE_MAX_COLUMNS = 62
 )
 
 var summaryCmd = &cobra.Command{
 	Use:   "summary",
 	Short: "Overview of this week's progress",
-	// Long:  `Overview for this week's progress by day.`,
-	Run: runSummary,
+	Long: `Overview of the tracked time by day, by project (--project), by language
+	(--language) or by tag (--tag). A task counts towards every one of its tags.
+	Without a period the current week is shown. Pick another one with
+	--week, --month or --year, which take a number (the ISO week, the month
+	or the year) or a negative offset like --week=-1 for the previous one,
+	with --from and --to, or use --all for the whole history.
+	Weeks begin on config.week_start, work days without tasks are listed
+	as well so gaps stand out. Below the days the progress towards the daily,
+	weekly, monthly and yearly goals of the config is shown, with --project
+	and --language the budgets of goals.projects and goals.languages.
+	With --chart the projects and languages are drawn as bars with their
+	share of the total time, and the days as a chart of the daily totals.`,
+	Example: `  goalkeeper summary --week=-1
+  goalkeeper summary --week 37
+  goalkeeper summary --project --month
+  goalkeeper summary --project --chart
+  goalkeeper summary --chart --month
+  goalkeeper summary --language --year 2023
+  goalkeeper summary --from "last monday" --to yesterday`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSummary,
 }
 
-func runSummary(cmd *cobra.Command, args []string) {
+func runSummary(cmd *cobra.Command, args []string) error {
 	project, err := cmd.Flags().GetBool("project")
 	if err != nil {
-		log.Fatalf("[summary] error getting project value: %v", err)
+		return fmt.Errorf("error getting project value: %w", err)
 	}
 
 	language, err := cmd.Flags().GetBool("language")
 	if err != nil {
-		log.Fatalf("[summary] error getting project value: %v", err)
+		return fmt.Errorf("error getting language value: %w", err)
+	}
+
+	tag, err := cmd.Flags().GetBool("tag")
+	if err != nil {
+		return fmt.Errorf("error getting tag value: %w", err)
 	}
 
 	ascending, err := cmd.Flags().GetBool("ascending")
 	if err != nil {
-		log.Fatalf("[summary] error getting project value: %v", err)
+		return fmt.Errorf("error getting ascending value: %w", err)
+	}
+
+	chart, err := cmd.Flags().GetBool("chart")
+	if err != nil {
+		return fmt.Errorf("error getting chart value: %w", err)
 	}
 
-	if !project && !language {
-		summaryWeek()
+	ref, err := summaryRef(cmd)
+	if err != nil {
+		return err
+	}
+
+	period, err := summaryPeriod(cmd, args, ref)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadTasks(period.From, period.To)
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	// goals of a past period are judged at its end
+	if period.IsBounded() && !period.To.After(ref) {
+		ref = period.LastDay()
+	}
+
+	if isStructured() {
+		progress, err := goalProgress(ref)
+		if err != nil {
+			return err
+		}
+		return printReport(cmd, pkg.NewSummaryReport(period, tasks, progress, location))
+	}
+
+	fmt.Printf("Summary for %s\n", period)
+
+	if !project && !language && !tag {
+		if chart {
+			summaryTimeline(tasks, period)
+		} else {
+			summaryDays(tasks, period)
+		}
+		return summaryGoals(ref)
 	}
 
 	if project {
-		summaryProjects(ascending)
+		summaryProjects(tasks, ascending, chart)
+		if err := summaryBudgets("Project", tomlConfig.GoalsSection.Projects, ref,
+			func(t *pkg.Task) string { return t.Project }); err != nil {
+			return err
+		}
 	}
 
 	if language {
-		summaryLanguages(ascending)
+		summaryLanguages(tasks, ascending, chart)
+		if err := summaryBudgets("Language", tomlConfig.GoalsSection.Languages, ref,
+			func(t *pkg.Task) string { return t.Language }); err != nil {
+			return err
+		}
 	}
 
+	if tag {
+		summaryTags(tasks, ascending, chart)
+	}
+
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(summaryCmd)
 
-	summaryCmd.Flags().BoolP("project", "p", false, "Show project summary")
-	summaryCmd.Flags().BoolP("language", "l", false, "Show language summary")
-	summaryCmd.Flags().BoolP("ascending", "a", false, "Show output in ascending order")
+	flags := summaryCmd.Flags()
+	flags.BoolP("project", "p", false, "Show project summary")
+	flags.BoolP("language", "l", false, "Show language summary")
+	flags.BoolP("tag", "t", false, "Show tag summary")
+	flags.BoolP("ascending", "a", false, "Show output in ascending order")
+	flags.BoolP("chart", "c", false, "Draw the summary as a chart")
+	flags.StringP("date", "d", "", "The day --week, --month and --year refer to, e.g. 2024-10-01 or -1w")
+
+	flags.String("from", "", "Show the tasks from this day on")
+	flags.String("to", "", "Show the tasks up to and including this day")
+	flags.IntP("week", "w", 0, "Show a week: the current one, an ISO week number or an offset like -1")
+	flags.IntP("month", "m", 0, "Show a month: the current one, a month number or an offset like -1")
+	flags.IntP("year", "y", 0, "Show a year: the current one, a year like 2023 or an offset like -1")
+	flags.Bool("all", false, "Show the whole history")
+
+	for _, name := range []string{"week", "month", "year"} {
+		flags.Lookup(name).NoOptDefVal = "0"
+	}
+
+	summaryCmd.MarkFlagsMutuallyExclusive("week", "month", "year", "all", "from")
+	summaryCmd.MarkFlagsMutuallyExclusive("week", "month", "year", "all", "to")
 }
 
-func summaryWeek() {
-	summary := make(map[time.Time][]*pkg.Task)
-	for _, t := range slices.Backward(tasks) {
-		if t.Start.Weekday() == time.Sunday {
-			break
+// summaryRef returns the day given with --date, otherwise now.
+func summaryRef(cmd *cobra.Command) (time.Time, error) {
+	if !cmd.Flags().Changed("date") {
+		return now(), nil
+	}
+
+	dateStr, err := cmd.Flags().GetString("date")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting date value: %w", err)
+	}
+
+	ref, err := pkg.ParseDate(dateStr, now())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--date: %w", err)
+	}
+	return ref, nil
+}
+
+// summaryPeriod returns the period selected by the flags of cmd, the week of
+// ref if none is given. The value of --week, --month or --year may also be
+// passed as the only argument, so "--week 37" works like "--week=37".
+func summaryPeriod(cmd *cobra.Command, args []string, ref time.Time) (pkg.Period, error) {
+	flags := cmd.Flags()
+
+	// periodValue returns the number given to the period flag name
+	periodValue := func(name string) (int, error) {
+		if len(args) == 1 && flags.Lookup(name).Value.String() == "0" {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return 0, fmt.Errorf("--%s: %q is not a number", name, args[0])
+			}
+			return n, nil
+		}
+
+		n, err := flags.GetInt(name)
+		if err != nil {
+			return 0, fmt.Errorf("error getting %s value: %w", name, err)
+		}
+		return n, nil
+	}
+
+	if len(args) == 1 && !flags.Changed("week") && !flags.Changed("month") && !flags.Changed("year") {
+		return pkg.Period{}, fmt.Errorf("unexpected argument %q", args[0])
+	}
+
+	switch {
+	case flags.Changed("all"):
+		return pkg.AllTime, nil
+
+	case flags.Changed("from") || flags.Changed("to"):
+		var from, to time.Time
+		for name, day := range map[string]*time.Time{"from": &from, "to": &to} {
+			if !flags.Changed(name) {
+				continue
+			}
+			value, err := flags.GetString(name)
+			if err != nil {
+				return pkg.Period{}, fmt.Errorf("error getting %s value: %w", name, err)
+			}
+			if *day, err = pkg.ParseDate(value, ref); err != nil {
+				return pkg.Period{}, fmt.Errorf("--%s: %w", name, err)
+			}
+		}
+		return pkg.DayRange(from, to)
+
+	case flags.Changed("month"):
+		n, err := periodValue("month")
+		if err != nil {
+			return pkg.Period{}, err
+		}
+		if n > 12 {
+			return pkg.Period{}, fmt.Errorf("--month: there is no month %d", n)
 		}
-		year, month, day := t.Start.Date()
-		date := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+		if n > 0 {
+			return pkg.Month(time.Date(ref.Year(), time.Month(n), 1, 0, 0, 0, 0, location)), nil
+		}
+		return pkg.Month(ref).Shift(n), nil
+
+	case flags.Changed("year"):
+		n, err := periodValue("year")
+		if err != nil {
+			return pkg.Period{}, err
+		}
+		if n > 0 {
+			return pkg.Year(time.Date(n, time.January, 1, 0, 0, 0, 0, location)), nil
+		}
+		return pkg.Year(ref).Shift(n), nil
+
+	default:
+		n, err := periodValue("week")
+		if err != nil {
+			return pkg.Period{}, err
+		}
+		if n > 0 {
+			year, _ := ref.ISOWeek()
+			period, err := pkg.ISOWeek(year, n, location)
+			if err != nil {
+				return pkg.Period{}, fmt.Errorf("--week: %w", err)
+			}
+			// the week starting on the configured day that holds the ISO week's Monday
+			return calendar.Week(period.From), nil
+		}
+		return calendar.Week(ref).Shift(n), nil
+	}
+}
+
+// summaryDays shows tasks grouped by day. Work days of a bounded period
+// without tasks are listed as well, up to today.
+func summaryDays(tasks []*pkg.Task, period pkg.Period) {
+	summary := make(map[time.Time][]*pkg.Task)
+	for _, t := range tasks {
+		date := pkg.StartOfDay(t.Start.In(location))
 		summary[date] = append(summary[date], t)
 	}
 
+	if period.IsBounded() {
+		for day := period.From; day.Before(period.To) && !day.After(now()); day = day.AddDate(0, 0, 1) {
+			if _, ok := summary[day]; !ok && calendar.IsWorkDay(day) {
+				summary[day] = nil
+			}
+		}
+	}
+
 	printSummary(summary)
 }
 
+// summaryGoals shows the progress towards every goal in the periods holding
+// ref.
+func summaryGoals(ref time.Time) error {
+	progress, err := goalProgress(ref)
+	if err != nil || len(progress) == 0 {
+		return err
+	}
+
+	tab := table.NewTable(
+		table.NewHeader("Goal"),
+		table.NewHeader("Period"),
+		table.NewHeader("Progress"),
+		table.NewHeader("Done", true),
+		table.NewHeader("Goal", true),
+		table.NewHeader("Per day left", true),
+	).WithRoundedCorners()
+
+	for _, p := range progress {
+		var perDay string
+		switch {
+		case p.Remaining() == 0:
+			perDay = "done"
+		case !p.Period.To.After(now()):
+			perDay = "missed"
+		case p.DaysLeft > 0:
+			perDay = fmt.Sprintf("%s (%d days)", formatDuration(p.PerDay()), p.DaysLeft)
+		default:
+			perDay = fmt.Sprintf("%s (no work days left)", formatDuration(p.PerDay()))
+		}
+
+		tab.AddRow([]string{
+			p.Goal.Name,
+			p.Period.String(),
+			fmt.Sprintf("%s %3d%%", table.ProgressBar(p.Ratio(), 20), int(p.Ratio()*100)),
+			formatDuration(p.Done),
+			formatDuration(p.Goal.Target),
+			perDay,
+		})
+	}
+
+	fmt.Println(tab)
+	return nil
+}
+
+// goalProgress returns the progress towards every goal in force at ref in
+// the periods holding ref.
+func goalProgress(ref time.Time) ([]pkg.Progress, error) {
+	goals := tomlConfig.GoalsSection.At(ref).Goals(calendar)
+	if len(goals) == 0 {
+		return nil, nil
+	}
+
+	span := pkg.GoalSpan(calendar, ref)
+	tasks, err := loadTasks(span.From, span.To)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	progress := make([]pkg.Progress, 0, len(goals))
+	for _, goal := range goals {
+		progress = append(progress, pkg.NewProgress(goal, goal.Period(ref), tasks, calendar, now()))
+	}
+	return progress, nil
+}
+
+// summaryBudgets shows how the time of every project or language with a
+// budget compares to its limits in the periods holding ref. field returns the
+// project or language of a task.
+func summaryBudgets(heading string, budgets map[string]pkg.Budget, ref time.Time, field func(*pkg.Task) string) error {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(budgets))
+	for name := range budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	span := pkg.GoalSpan(calendar, ref)
+	tasks, err := loadTasks(span.From, span.To)
+	if err != nil {
+		return fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	tab := table.NewTable(
+		table.NewHeader(heading),
+		table.NewHeader("Period"),
+		table.NewHeader("Done", true),
+		table.NewHeader("Min", true),
+		table.NewHeader("Max", true),
+		table.NewHeader("Status"),
+	).WithRoundedCorners()
+
+	var warnings []string
+
+	for _, name := range names {
+		match := func(t *pkg.Task) bool { return field(t) == name }
+
+		for _, c := range budgets[name].Check(name, match, tasks, calendar, ref) {
+			status := "ok"
+			switch {
+			case c.Over() > 0:
+				status = "over budget by " + formatDuration(c.Over())
+				warnings = append(warnings, fmt.Sprintf("%s: %s (%s %s)", name, status, c.Kind, c.Period))
+			case c.Missing() > 0 && !c.Period.To.After(now()):
+				status = "under target by " + formatDuration(c.Missing())
+				warnings = append(warnings, fmt.Sprintf("%s: %s (%s %s)", name, status, c.Kind, c.Period))
+			case c.Missing() > 0:
+				status = formatDuration(c.Missing()) + " to go"
+			}
+
+			tab.AddRow([]string{
+				name,
+				fmt.Sprintf("%s %s", c.Kind, c.Period),
+				formatDuration(c.Done),
+				formatLimit(c.Min),
+				formatLimit(c.Max),
+				status,
+			})
+		}
+	}
+
+	fmt.Println(tab)
+
+	for _, w := range warnings {
+		warn("%s", w)
+	}
+	return nil
+}
+
+func formatLimit(limit time.Duration) string {
+	if limit == 0 {
+		return "-"
+	}
+	return formatDuration(limit)
+}
+
 func printSummary(summary map[time.Time][]*pkg.Task) {
 	weekdays := make([]time.Time, 0, len(summary))
 	for t := range summary {
@@ -97,10 +446,14 @@ func printSummary(summary map[time.Time][]*pkg.Task) {
 	for j, weekday := range weekdays {
 		// var amountToday time.Duration
 
-		for i, t := range slices.Backward(summary[weekday]) {
+		if len(summary[weekday]) == 0 {
+			table.AddRow([]string{weekday.Format(pkg.DateFormat), "-", "-", formatDuration(0)})
+		}
+
+		for i, t := range summary[weekday] {
 
 			var weekdayStr string
-			if i == len(summary[weekday])-1 {
+			if i == 0 {
 				weekdayStr = weekday.Format(pkg.DateFormat)
 			}
 
@@ -120,7 +473,7 @@ func printSummary(summary map[time.Time][]*pkg.Task) {
 	fmt.Println(table.String())
 }
 
-func summaryProjects(ascending bool) {
+func summaryProjects(tasks []*pkg.Task, ascending, chart bool) {
 	projectTasks := map[string]time.Duration{}
 	for _, t := range tasks {
 		projectTasks[t.Project] += t.Duration()
@@ -141,6 +494,11 @@ func summaryProjects(ascending bool) {
 		})
 	}
 
+	if chart {
+		printShares(projectNames, projectTasks, pkg.TotalDuration(tasks))
+		return
+	}
+
 	tab := table.NewTable(
 		table.NewHeader("Projects").HeadingCentered(),
 		table.NewHeader("Duration", true),
@@ -153,9 +511,13 @@ func summaryProjects(ascending bool) {
 	fmt.Println(tab)
 }
 
-func summaryLanguages(ascending bool) {
+func summaryLanguages(tasks []*pkg.Task, ascending, chart bool) {
 	languageTasks := map[string]time.Duration{}
 	for _, t := range tasks {
+		if t.Language == "" {
+			languageTasks[NO_LANGUAGE] += t.Duration()
+			continue
+		}
 		languageTasks[t.Language] += t.Duration()
 	}
 
@@ -174,6 +536,11 @@ func summaryLanguages(ascending bool) {
 		})
 	}
 
+	if chart {
+		printShares(languageNames, languageTasks, pkg.TotalDuration(tasks))
+		return
+	}
+
 	tab := table.NewTable(
 		table.NewHeader("Languages", true),
 		table.NewHeader("Duration", true),
@@ -185,3 +552,125 @@ func summaryLanguages(ascending bool) {
 
 	fmt.Println(tab)
 }
+
+const (
+	// UNTAGGED is the name tasks without tags are summed up under.
+	UNTAGGED = "(untagged)"
+	// NO_LANGUAGE is the name tasks without a language are summed up under.
+	NO_LANGUAGE = "(none)"
+)
+
+func summaryTags(tasks []*pkg.Task, ascending, chart bool) {
+	tagTasks := map[string]time.Duration{}
+	for _, t := range tasks {
+		if len(t.Tags) == 0 {
+			tagTasks[UNTAGGED] += t.Duration()
+		}
+		for _, tag := range t.Tags {
+			tagTasks["#"+tag] += t.Duration()
+		}
+	}
+
+	tagNames := make([]string, 0, len(tagTasks))
+	for k := range tagTasks {
+		tagNames = append(tagNames, k)
+	}
+
+	sort.Slice(tagNames, func(i, j int) bool {
+		if ascending {
+			return tagTasks[tagNames[i]] < tagTasks[tagNames[j]]
+		}
+		return tagTasks[tagNames[i]] > tagTasks[tagNames[j]]
+	})
+
+	if chart {
+		printShares(tagNames, tagTasks, pkg.TotalDuration(tasks))
+		return
+	}
+
+	tab := table.NewTable(
+		table.NewHeader("Tags", true),
+		table.NewHeader("Duration", true),
+	).WithRoundedCorners()
+
+	for _, name := range tagNames {
+		tab.AddRow([]string{name, formatDuration(tagTasks[name])})
+	}
+
+	fmt.Println(tab)
+}
+
+// printShares draws a bar for every name with its duration and its share of
+// total. A task can count towards several names, so total is passed in
+// instead of adding up durations.
+func printShares(names []string, durations map[string]time.Duration, total time.Duration) {
+	chart := table.NewBarChart(CHART_WIDTH)
+	for _, name := range names {
+		share := 0.0
+		if total > 0 {
+			share = float64(durations[name]) / float64(total)
+		}
+		chart.Add(name, durations[name].Hours(),
+			fmt.Sprintf("%8s %3.0f%%", formatDuration(durations[name]), share*100))
+	}
+
+	fmt.Print(chart)
+	fmt.Printf("Total: %s\n\n", formatDuration(total))
+}
+
+// summaryTimeline draws the daily totals of period up to today. Periods of
+// up to two months get a bar for every day, longer ones a sparkline for every
+// month.
+func summaryTimeline(tasks []*pkg.Task, period pkg.Period) {
+	totals := pkg.DailyTotals(tasks, location)
+
+	from, to := period.From, period.To
+	if from.IsZero() {
+		from = pkg.StartOfDay(now())
+		for day := range totals {
+			if day.Before(from) {
+				from = day
+			}
+		}
+	}
+	if today := pkg.StartOfDay(now()).AddDate(0, 0, 1); to.IsZero() || to.After(today) {
+		to = today
+	}
+
+	var (
+		values  []float64
+		labels  []string
+		total   time.Duration
+		longest time.Duration
+	)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		values = append(values, totals[day].Hours())
+		labels = append(labels, strconv.Itoa(day.Day()))
+		total += totals[day]
+		longest = max(longest, totals[day])
+	}
+
+	if len(values) == 0 {
+		fmt.Printf("No days to show yet.\n\n")
+		return
+	}
+
+	if len(values) <= TIMELINE_MAX_COLUMNS {
+		fmt.Print(table.Columns(values, labels, TIMELINE_HEIGHT))
+	} else {
+		for month := pkg.Month(from); month.From.Before(to); month = month.Shift(1) {
+			var monthValues []float64
+			for day := month.From; day.Before(month.To); day = day.AddDate(0, 0, 1) {
+				if day.Before(from) || !day.Before(to) {
+					monthValues = append(monthValues, 0)
+					continue
+				}
+				monthValues = append(monthValues, totals[day].Hours())
+			}
+			fmt.Printf("%s %s\n", month.From.Format("2006-01"), table.Sparkline(monthValues))
+		}
+	}
+
+	fmt.Printf("Total: %s, best day: %s, average: %s\n\n", formatDuration(total),
+		formatDuration(longest), formatDuration(total/time.Duration(len(values))))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var switchCmd = &cobra.Command{
+	Use:   "switch",
+	Short: "Ends the running task and starts a new one.",
+	Long: `Ends the running task and starts a new one for the given project at the same time.
+	The language defaults to the one of the project like with "start".
+	Use --at to switch earlier, e.g. "--at 14:00" or "--at -10m".
+	--tag and --note describe the new task.`,
+	Example: `  goalkeeper switch -p website
+  goalkeeper switch -p goalkeeper -l Go --at -15m`,
+	Args: cobra.NoArgs,
+	RunE: runSwitch,
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+
+	switchCmd.Flags().StringP("project", "p", "", "The project of the new task")
+	switchCmd.Flags().StringP("language", "l", "", "The language of the new task, defaults to the one of the project")
+	switchCmd.Flags().String("at", "", "Switch at this time instead of now")
+	addTaskFlags(switchCmd)
+
+	switchCmd.MarkFlagRequired("project")
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	if lastTask == nil || lastTask.IsFinished() {
+		return errors.New("no task is running, call 'start' instead")
+	}
+
+	project, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return fmt.Errorf("error getting project value: %w", err)
+	}
+
+	language, err := taskLanguage(cmd, project)
+	if err != nil {
+		return err
+	}
+
+	at := now()
+	if cmd.Flags().Changed("at") {
+		if at, err = parseTimeFlag(cmd, "at", now()); err != nil {
+			return err
+		}
+		if at.After(now()) {
+			return fmt.Errorf("--at: %s is in the future", at.Format(pkg.DateTimeFormat))
+		}
+	}
+
+	ended, next := lastTask.Switch(project, language, at)
+	if err := checkEnded(ended); err != nil {
+		return err
+	}
+	if err := applyTaskFlags(cmd, next); err != nil {
+		return err
+	}
+
+	if err := ended.Validate(); err != nil {
+		return err
+	}
+	if err := checkOverlap(next, ended); err != nil {
+		return err
+	}
+
+	if err := store.ApplyAs(pkg.OpSwitch,
+		pkg.Change{Before: lastTask, After: ended},
+		pkg.Change{After: next},
+	); err != nil {
+		return fmt.Errorf("error saving tasks: %w", err)
+	}
+
+	if isStructured() {
+		return printReport(cmd, pkg.NewTaskReport(next))
+	}
+
+	log.Printf(
+		"Switched from %s after %s to %s at %s\n",
+		ended.Label(),
+		formatDuration(ended.Duration()),
+		next.Label(),
+		next.Start.Format(pkg.TimeFormat),
+	)
+	return nil
+}
+
+// lastLanguage returns the language of the most recent task of project, or
+// "" if there is none.
+func lastLanguage(project string) (string, error) {
+	tasks, err := store.Query(pkg.TaskFilter{Project: project})
+	if err := checkLoad(err); err != nil {
+		return "", fmt.Errorf("error loading tasks: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		return "", nil
+	}
+	return tasks[len(tasks)-1].Language, nil
+}
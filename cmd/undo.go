@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aaronbittel/goalkeeper/pkg"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Reverts the last change.",
+	Long: `Reverts the most recent start, end, edit or delete.
+	Every change is recorded in a journal next to the task file, so "undo" can be
+	repeated to step further back. "redo" applies an undone change again.
+	"restore", "doctor --fix" and schema upgrades replace the task file, the
+	changes before them can not be undone. Changes that no longer fit the
+	tasks, e.g. after editing the file by hand, are skipped.`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Applies the last undone change again.",
+	Args:  cobra.NoArgs,
+	RunE:  runRedo,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	entry, err := store.Undo()
+	if errors.Is(err, pkg.ErrNothingToUndo) {
+		fmt.Fprintln(os.Stderr, capitalize(err.Error()))
+		return nil
+	}
+	if errors.Is(err, pkg.ErrEntrySkipped) {
+		return fmt.Errorf("%w\nRun 'undo' again to undo the change before it.", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Undid %s\n", entry.Op)
+	for _, c := range slices.Backward(entry.Changes) {
+		printChange(c.Inverse())
+	}
+	return nil
+}
+
+func runRedo(cmd *cobra.Command, args []string) error {
+	entry, err := store.Redo()
+	if errors.Is(err, pkg.ErrNothingToRedo) {
+		fmt.Fprintln(os.Stderr, capitalize(err.Error()))
+		return nil
+	}
+	if errors.Is(err, pkg.ErrEntrySkipped) {
+		return fmt.Errorf("%w\nRun 'redo' again to redo the change after it.", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Redid %s\n", entry.Op)
+	for _, c := range entry.Changes {
+		printChange(c)
+	}
+	return nil
+}
+
+// printChange shows what c did to the stored tasks.
+func printChange(c pkg.Change) {
+	switch {
+	case c.Before == nil && c.After != nil:
+		fmt.Printf("\tadded   %s: %s\n", c.After.ID, describeTask(c.After))
+	case c.After == nil && c.Before != nil:
+		fmt.Printf("\tremoved %s: %s\n", c.Before.ID, describeTask(c.Before))
+	case c.Before != nil:
+		fmt.Printf("\tchanged %s: %s\n\t     to %s: %s\n",
+			c.Before.ID, describeTask(c.Before), c.After.ID, describeTask(c.After))
+	}
+}
+
+// capitalize turns a message from pkg into a sentence.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,128 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// sparkLevels are the blocks from one eighth to a full cell high.
+var sparkLevels = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// horizontalBar returns a bar that is eighths eighths of a cell long.
+func horizontalBar(eighths int) string {
+	bar := strings.Repeat(BarFull, eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += barEighths[rest-1]
+	}
+	return bar
+}
+
+type barRow struct {
+	label string
+	value float64
+	text  string
+}
+
+// BarChart renders labeled values as horizontal bars, scaled so the largest
+// value fills the whole width.
+type BarChart struct {
+	rows  []barRow
+	width int
+}
+
+// NewBarChart creates a chart whose longest bar is width cells long.
+func NewBarChart(width int) *BarChart {
+	return &BarChart{width: width}
+}
+
+// Add adds a bar for value. text is shown behind the bar.
+func (c *BarChart) Add(label string, value float64, text string) {
+	c.rows = append(c.rows, barRow{label: label, value: value, text: text})
+}
+
+func (c BarChart) String() string {
+	var (
+		labelWidth int
+		maxValue   float64
+	)
+	for _, r := range c.rows {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(r.label))
+		maxValue = math.Max(maxValue, r.value)
+	}
+
+	b := new(strings.Builder)
+	for _, r := range c.rows {
+		eighths := 0
+		if maxValue > 0 {
+			eighths = int(math.Round(r.value / maxValue * float64(c.width*8)))
+		}
+
+		bar := horizontalBar(eighths)
+		padding := c.width - utf8.RuneCountInString(bar)
+
+		fmt.Fprintf(b, "%s%s %s%s %s\n",
+			r.label, strings.Repeat(" ", labelWidth-utf8.RuneCountInString(r.label)),
+			bar, strings.Repeat(" ", padding), r.text)
+	}
+	return b.String()
+}
+
+// Columns renders values as vertical bars that are at most height lines high,
+// each with a label of up to two characters below it.
+func Columns(values []float64, labels []string, height int) string {
+	maxValue := 0.0
+	for _, v := range values {
+		maxValue = math.Max(maxValue, v)
+	}
+
+	units := make([]int, len(values))
+	for i, v := range values {
+		if maxValue > 0 {
+			units[i] = int(math.Round(v / maxValue * float64(height*8)))
+		}
+	}
+
+	b := new(strings.Builder)
+	for line := height - 1; line >= 0; line-- {
+		row := new(strings.Builder)
+		for _, u := range units {
+			switch {
+			case u >= (line+1)*8:
+				row.WriteString(strings.Repeat(sparkLevels[7], 2))
+			case u > line*8:
+				row.WriteString(strings.Repeat(sparkLevels[u-line*8-1], 2))
+			default:
+				row.WriteString("  ")
+			}
+			row.WriteString(" ")
+		}
+		b.WriteString(strings.TrimRight(row.String(), " ") + "\n")
+	}
+
+	for _, label := range labels {
+		fmt.Fprintf(b, "%-2s ", label)
+	}
+	return strings.TrimRight(b.String(), " ") + "\n"
+}
+
+// Sparkline renders values as a single line of blocks, scaled so the largest
+// value is a full block. Zero values are shown as spaces.
+func Sparkline(values []float64) string {
+	maxValue := 0.0
+	for _, v := range values {
+		maxValue = math.Max(maxValue, v)
+	}
+
+	b := new(strings.Builder)
+	for _, v := range values {
+		if v <= 0 || maxValue == 0 {
+			b.WriteString(" ")
+			continue
+		}
+		level := int(math.Ceil(v/maxValue*float64(len(sparkLevels)))) - 1
+		b.WriteString(sparkLevels[max(level, 0)])
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package table
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// heatShades are the cells of the levels without colors.
+var heatShades = []string{"·", "░", "▒", "▓", "█"}
+
+// heatColors are 256 color codes of the levels, from gray to bright green.
+var heatColors = []int{237, 22, 28, 34, 40}
+
+// Heatmap renders a grid of cells, each shaded by its level from 0 to 4.
+// Every cell is two characters wide.
+type Heatmap struct {
+	rowLabels []string
+	levels    [][]int
+	topLines  []map[int]string
+	colors    bool
+}
+
+// NewHeatmap creates a heatmap with a row for every label and cols columns.
+// All cells start out blank.
+func NewHeatmap(rowLabels []string, cols int) *Heatmap {
+	levels := make([][]int, len(rowLabels))
+	for i := range levels {
+		levels[i] = make([]int, cols)
+		for j := range levels[i] {
+			levels[i][j] = -1
+		}
+	}
+	return &Heatmap{rowLabels: rowLabels, levels: levels}
+}
+
+// Set sets the level of a cell, -1 leaves it blank.
+func (h *Heatmap) Set(row, col, level int) {
+	h.levels[row][col] = min(level, len(heatShades)-1)
+}
+
+// AddTopLine adds a line above the grid. Every label starts at its column,
+// labels past the last column are left out.
+func (h *Heatmap) AddTopLine(labels map[int]string) {
+	h.topLines = append(h.topLines, labels)
+}
+
+// WithColors shades the cells with ANSI colors instead of block characters.
+func (h *Heatmap) WithColors() *Heatmap {
+	h.colors = true
+	return h
+}
+
+func (h Heatmap) cols() int {
+	if len(h.levels) == 0 {
+		return 0
+	}
+	return len(h.levels[0])
+}
+
+func (h Heatmap) labelWidth() int {
+	width := 0
+	for _, l := range h.rowLabels {
+		width = max(width, len(l))
+	}
+	return width
+}
+
+func (h Heatmap) cell(level int) string {
+	if level < 0 {
+		return "  "
+	}
+	if h.colors {
+		return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m ", heatColors[level])
+	}
+	return heatShades[level] + " "
+}
+
+func (h Heatmap) String() string {
+	b := new(strings.Builder)
+	indent := strings.Repeat(" ", h.labelWidth()+1)
+
+	for _, labels := range h.topLines {
+		cols := make([]int, 0, len(labels))
+		for col := range labels {
+			if col < h.cols() {
+				cols = append(cols, col)
+			}
+		}
+		slices.Sort(cols)
+
+		line := new(strings.Builder)
+		for _, col := range cols {
+			// labels that would run into the previous one are left out
+			pos := utf8.RuneCountInString(line.String())
+			if pos > 0 && pos >= 2*col {
+				continue
+			}
+			line.WriteString(strings.Repeat(" ", 2*col-pos))
+			line.WriteString(labels[col])
+		}
+		b.WriteString(indent + line.String() + "\n")
+	}
+
+	for i, label := range h.rowLabels {
+		fmt.Fprintf(b, "%-*s ", h.labelWidth(), label)
+		for _, level := range h.levels[i] {
+			b.WriteString(h.cell(level))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// Legend returns a line showing the cell of every level followed by its
+// label.
+func (h Heatmap) Legend(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for level, label := range labels {
+		parts = append(parts, h.cell(level)+label)
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,25 @@
+package table
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	BarFull  = "█"
+	BarEmpty = "░"
+)
+
+// barEighths are the partial blocks from one eighth to seven eighths of a cell.
+var barEighths = []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// ProgressBar renders ratio as a bar that is width cells wide. Ratios above 1
+// fill the whole bar.
+func ProgressBar(ratio float64, width int) string {
+	ratio = math.Max(0, math.Min(ratio, 1))
+	eighths := int(math.Round(ratio * float64(width*8)))
+
+	bar := horizontalBar(eighths)
+	return bar + strings.Repeat(BarEmpty, width-utf8.RuneCountInString(bar))
+}
@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_BACKUP_DIR = "backups"
+	MAX_BACKUPS        = 10
+
+	backupTimeFormat = "20060102-150405.000"
+)
+
+type Backup struct {
+	Name    string
+	Path    string
+	Created time.Time
+}
+
+func BackupDir() (string, error) {
+	return dataPath(DEFAULT_BACKUP_DIR)
+}
+
+// BackupFile copies the file at path into the backup directory and removes
+// the oldest backups of that file so that at most MAX_BACKUPS are kept.
+// Missing or empty files are not backed up.
+func BackupFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+
+	dir, err := BackupDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	name := backupName(filepath.Base(path), time.Now())
+	if err := copyFile(path, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("error backing up %s: %w", path, err)
+	}
+
+	return pruneBackups(filepath.Base(path), MAX_BACKUPS)
+}
+
+// ListBackups returns all backups of filename, newest first.
+func ListBackups(filename string) ([]Backup, error) {
+	dir, err := BackupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Backup{}, nil
+		}
+		return nil, err
+	}
+
+	base, ext := splitExt(filename)
+	prefix := base + "-"
+
+	backups := []Backup{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		created, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Name:    name,
+			Path:    filepath.Join(dir, name),
+			Created: created,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+
+	return backups, nil
+}
+
+// RestoreBackup replaces filename with the content of backup. The current
+// file is backed up first, so a restore can itself be rolled back. backup is
+// read before, as that may rotate it out. The changes in the journal can not
+// be undone after a restore.
+func RestoreBackup(filename string, backup Backup) error {
+	path, err := dataPath(filename)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("error reading backup %s: %w", backup.Name, err)
+	}
+
+	if err := BackupFile(path); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return checkpoint(path, OpRestore)
+}
+
+// writeFileAtomic writes to a temporary file next to path, syncs it to disk
+// and renames it over path, so path either has the old or the new content.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error syncing %s: %w", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+
+	// Persist the rename itself
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFileAtomic(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+func pruneBackups(filename string, keep int) error {
+	backups, err := ListBackups(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) <= keep {
+		return nil
+	}
+
+	for _, b := range backups[keep:] {
+		if err := os.Remove(b.Path); err != nil {
+			return fmt.Errorf("error removing old backup %s: %w", b.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func backupName(filename string, t time.Time) string {
+	base, ext := splitExt(filename)
+	return fmt.Sprintf("%s-%s%s", base, t.Format(backupTimeFormat), ext)
+}
+
+func splitExt(filename string) (string, string) {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext), ext
+}
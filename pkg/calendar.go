@@ -0,0 +1,43 @@
+package pkg
+
+import "time"
+
+// Calendar describes the working week: the day weeks begin on and the days
+// that are worked. The other days are rest days.
+type Calendar struct {
+	WeekStart time.Weekday
+	WorkDays  [7]bool
+}
+
+// DefaultCalendar has ISO weeks and works Monday to Friday.
+var DefaultCalendar = Calendar{
+	WeekStart: time.Monday,
+	WorkDays: [7]bool{
+		time.Monday:    true,
+		time.Tuesday:   true,
+		time.Wednesday: true,
+		time.Thursday:  true,
+		time.Friday:    true,
+	},
+}
+
+// Week returns the week of t.
+func (c Calendar) Week(t time.Time) Period {
+	return Week(t, c.WeekStart)
+}
+
+// IsWorkDay reports whether the day of t is worked.
+func (c Calendar) IsWorkDay(t time.Time) bool {
+	return c.WorkDays[t.Weekday()]
+}
+
+// WorkDaysIn returns the number of work days in the bounded period p.
+func (c Calendar) WorkDaysIn(p Period) int {
+	days := 0
+	for d := p.From; d.Before(p.To); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkDay(d) {
+			days++
+		}
+	}
+	return days
+}
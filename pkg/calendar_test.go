@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigCalendar(t *testing.T) {
+	config := ConfigSection{WeekStart: "Sun", WorkDays: []string{"sunday", "Mon", "tue"}}
+
+	calendar, err := config.Calendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calendar.WeekStart != time.Sunday {
+		t.Errorf("expected weeks to begin on Sunday, got %s", calendar.WeekStart)
+	}
+
+	// 2024-09-29 is a Sunday
+	week := calendar.Week(time.Date(2024, 10, 1, 12, 0, 0, 0, testLocation))
+	if want := time.Date(2024, 9, 29, 0, 0, 0, 0, testLocation); !week.From.Equal(want) {
+		t.Errorf("expected the week to begin on %s, got %s", want, week.From)
+	}
+	if days := calendar.WorkDaysIn(week); days != 3 {
+		t.Errorf("expected 3 work days, got %d", days)
+	}
+
+	calendar, err = ConfigSection{}.Calendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calendar != DefaultCalendar {
+		t.Errorf("expected the default calendar without settings, got %+v", calendar)
+	}
+
+	for _, config := range []ConfigSection{
+		{WeekStart: "someday"},
+		{WorkDays: []string{"monday", "funday"}},
+	} {
+		if _, err := config.Calendar(); !errors.Is(err, ErrConfigInvalid) {
+			t.Errorf("expected ErrConfigInvalid for %+v, got %v", config, err)
+		}
+	}
+}
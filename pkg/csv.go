@@ -1,71 +1,377 @@
 package pkg
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
-func LoadTasks(filename string) ([]*Task, error) {
-	path := filepath.Join(DefaultPath(), filename)
-	f, err := os.OpenFile(path, os.O_RDONLY, 0644)
+// LoadTasks reads all tasks from filename. Malformed rows are skipped: the
+// tasks that could be read are returned together with a *SkippedRowsError
+// listing the rows that were left out.
+func LoadTasks(filename string, loc *time.Location) ([]*Task, error) {
+	path, err := dataPath(filename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, err
-		}
-		log.Fatalf("[load] error opening file %s: %v", filename, err)
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = migrateCSV(path, data)
+	if err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
-	records, err := csv.NewReader(f).ReadAll()
+	_, body, offset, err := splitSchemaLine(data)
 	if err != nil {
-		// log.Fatalf("error reading csv: %v", err)
 		return nil, err
 	}
+	lines := strings.Split(string(body), "\n")
 
-	if len(records) == 0 {
-		return []*Task{}, nil
+	// raw returns the original text of the lines [start, end] of body
+	raw := func(start, end int) string {
+		if start < 1 || end > len(lines) || start > end {
+			return ""
+		}
+		return strings.TrimRight(strings.Join(lines[start-1:end], "\n"), "\r")
 	}
 
-	// Dismiss column names
-	records = records[1:]
+	reader := csv.NewReader(bytes.NewReader(body))
+	// Rows with a wrong number of fields are reported by FromFields
+	reader.FieldsPerRecord = -1
+
+	tasks := []*Task{}
+	skipped := &SkippedRowsError{Filename: filename}
+	isHeader := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			skipped.Rows = append(skipped.Rows, &MalformedRowError{
+				Line:  parseErr.StartLine + offset,
+				Field: "row",
+				Value: raw(parseErr.StartLine, parseErr.Line),
+				Raw:   raw(parseErr.StartLine, parseErr.Line),
+				Err:   parseErr.Err,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", filename, err)
+		}
+
+		// Dismiss column names
+		if isHeader {
+			isHeader = false
+			continue
+		}
 
-	tasks := make([]*Task, 0, len(records))
+		task, err := FromFields(record, loc)
+		if err != nil {
+			var rowErr *MalformedRowError
+			if !errors.As(err, &rowErr) {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+
+			start, _ := reader.FieldPos(0)
+			end, _ := reader.FieldPos(len(record) - 1)
+			rowErr.Line = start + offset
+			rowErr.Raw = raw(start, end)
+
+			skipped.Rows = append(skipped.Rows, rowErr)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
 
-	for _, record := range records {
-		tasks = append(tasks, FromFields(record))
+	if len(skipped.Rows) > 0 {
+		return tasks, skipped
 	}
 
 	return tasks, nil
 }
 
-// TODO: Save to new file / backup old file, if error occurs restore old file
-func SaveTasks(filename string, tasks []*Task) {
-	path := filepath.Join(DefaultPath(), filename)
-	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+// SaveTasks writes all tasks to filename. The previous content is kept in the
+// backup directory and the new content is written to a temporary file that is
+// renamed over the old one, so a crash never leaves a half written file behind.
+// Tasks without an ID are assigned a new one.
+func SaveTasks(filename string, tasks []*Task) error {
+	return saveTasks(filename, tasks, nil)
+}
+
+// saveTasks works like SaveTasks but keeps the malformed rows of skipped at
+// the end of the file, so they are not lost before they are quarantined.
+func saveTasks(filename string, tasks []*Task, skipped *SkippedRowsError) error {
+	path, err := dataPath(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := BackupFile(path); err != nil {
+		return fmt.Errorf("error creating backup of %s: %w", filename, err)
+	}
+
+	ids := newIDSet(tasks...)
+	for _, task := range tasks {
+		if task.ID == "" {
+			task.ID = ids.next()
+		}
+	}
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		if _, err := fmt.Fprintln(w, schemaLine(CSV_SCHEMA_VERSION)); err != nil {
+			return fmt.Errorf("error writing schema version to file: %w", err)
+		}
+
+		writer := csv.NewWriter(w)
+
+		if err := writer.Write(csvColumns); err != nil {
+			return fmt.Errorf("error writing csv column names to file: %w", err)
+		}
+
+		for _, task := range tasks {
+			if err := writer.Write(task.Fields()); err != nil {
+				return fmt.Errorf("error writing %s to %s: %w", task.Project, filename, err)
+			}
+		}
+		writer.Flush()
+
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("error flushing csv writer: %w", err)
+		}
+
+		for _, row := range skipped.rows() {
+			if _, err := fmt.Fprintln(w, row.Raw); err != nil {
+				return fmt.Errorf("error writing malformed row to %s: %w", filename, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// QuarantineRows moves all malformed rows of filename to the quarantine file
+// and rewrites filename without them. It returns the rows that were moved,
+// nil if there were none.
+func QuarantineRows(filename string, loc *time.Location) (*SkippedRowsError, error) {
+	tasks, err := LoadTasks(filename, loc)
+
+	var skipped *SkippedRowsError
+	if !errors.As(err, &skipped) {
+		return nil, err
+	}
+
+	path, err := dataPath(DEFAULT_QUARANTINE_NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Fatalf("[save] error opening file %s: %v", filename, err)
+		return nil, fmt.Errorf("error opening %s: %w", DEFAULT_QUARANTINE_NAME, err)
 	}
 	defer f.Close()
 
-	writer := csv.NewWriter(f)
+	for _, row := range skipped.Rows {
+		if _, err := fmt.Fprintln(f, row.Raw); err != nil {
+			return nil, fmt.Errorf("error writing to %s: %w", DEFAULT_QUARANTINE_NAME, err)
+		}
+	}
 
-	if err := writer.Write([]string{"Category", "Title", "Start", "End"}); err != nil {
-		log.Fatalf("error writing csv column names to file")
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("error syncing %s: %w", DEFAULT_QUARANTINE_NAME, err)
 	}
 
-	for _, task := range tasks {
-		if writer.Write(task.Fields()); err != nil {
-			log.Fatalf("error writing %s to %s: %v", task.Project, filename, err)
+	if err := SaveTasks(filename, tasks); err != nil {
+		return nil, err
+	}
+
+	filePath, err := dataPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkpoint(filePath, OpQuarantine); err != nil {
+		return nil, err
+	}
+
+	return skipped, nil
+}
+
+// CSVStore keeps all tasks in a single csv file. Every operation reads the
+// whole file and every change rewrites it.
+type CSVStore struct {
+	filename string
+	loc      *time.Location
+}
+
+func NewCSVStore(filename string, loc *time.Location) *CSVStore {
+	return &CSVStore{filename: filename, loc: loc}
+}
+
+// all returns every task of the file. A *SkippedRowsError is returned as the
+// second value instead of as an error, so writes can carry the malformed
+// rows over into the new file.
+func (s *CSVStore) all() ([]*Task, *SkippedRowsError, error) {
+	tasks, err := LoadTasks(s.filename, s.loc)
+
+	var skipped *SkippedRowsError
+	switch {
+	case err == nil:
+		return tasks, nil, nil
+	case errors.As(err, &skipped):
+		return tasks, skipped, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return []*Task{}, nil, nil
+	default:
+		return nil, nil, err
+	}
+}
+
+// skippedErr converts skipped into an error without creating a non-nil
+// error interface holding a nil pointer.
+func skippedErr(skipped *SkippedRowsError) error {
+	if skipped == nil {
+		return nil
+	}
+	return skipped
+}
+
+func (s *CSVStore) Load(from, to time.Time) ([]*Task, error) {
+	return s.Query(TaskFilter{From: from, To: to})
+}
+
+func (s *CSVStore) Query(filter TaskFilter) ([]*Task, error) {
+	tasks, skipped, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []*Task{}
+	for _, t := range tasks {
+		if filter.Match(t) {
+			matching = append(matching, t)
 		}
 	}
-	writer.Flush()
+	return matching, skippedErr(skipped)
+}
+
+func (s *CSVStore) Last() (*Task, error) {
+	tasks, skipped, err := s.all()
+	if err != nil || len(tasks) == 0 {
+		return nil, err
+	}
+	return tasks[len(tasks)-1], skippedErr(skipped)
+}
+
+func (s *CSVStore) Get(id string) (*Task, error) {
+	tasks, _, err := s.all()
+	if err != nil {
+		return nil, err
+	}
 
-	if writer.Error() != nil {
-		log.Fatalf("error flushing csv writer: %v", err)
+	idx := s.index(tasks, &Task{ID: id})
+	if idx == -1 {
+		return nil, ErrTaskNotFound
 	}
+	return tasks[idx], nil
+}
+
+func (s *CSVStore) Append(task *Task) error {
+	return s.Apply(Change{After: task})
+}
+
+func (s *CSVStore) Update(task *Task) error {
+	before, err := s.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before, After: task})
+}
+
+func (s *CSVStore) Delete(task *Task) error {
+	before, err := s.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before})
+}
+
+// Apply makes all changes with a single save of the file.
+func (s *CSVStore) Apply(changes ...Change) error {
+	tasks, skipped, err := s.all()
+	if err != nil {
+		return err
+	}
+
+	ids := newIDSet(tasks...)
+
+	for _, c := range changes {
+		if c.Before == nil {
+			if c.After == nil {
+				continue
+			}
+			if c.After.ID == "" {
+				c.After.ID = ids.next()
+			} else if ids[c.After.ID] {
+				return fmt.Errorf("%w: %s", ErrTaskExists, c.After.ID)
+			}
+			ids[c.After.ID] = true
+			tasks = append(tasks, c.After)
+			continue
+		}
+
+		idx := s.index(tasks, c.Before)
+		if idx == -1 {
+			return fmt.Errorf("%w: %s", ErrTaskNotFound, c.Before.ID)
+		}
+		if !tasks[idx].Equal(*c.Before) {
+			return fmt.Errorf("%w: %s", ErrTaskChanged, c.Before.ID)
+		}
+
+		if c.After == nil {
+			delete(ids, c.Before.ID)
+			tasks = slices.Delete(tasks, idx, idx+1)
+			continue
+		}
+
+		if c.After.ID != c.Before.ID {
+			return fmt.Errorf("the ID of task %s can not be changed", c.Before.ID)
+		}
+		tasks[idx] = c.After
+	}
+
+	sortTasks(tasks)
+	return saveTasks(s.filename, tasks, skipped)
+}
+
+func (s *CSVStore) Close() error {
+	return nil
+}
+
+func (s *CSVStore) index(tasks []*Task, task *Task) int {
+	return slices.IndexFunc(tasks, func(t *Task) bool {
+		return t.ID == task.ID
+	})
+}
 
-	fmt.Printf("Successfully saved record to %s\n", filename)
+func sortTasks(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Start.Before(tasks[j].Start)
+	})
 }
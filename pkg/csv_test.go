@@ -0,0 +1,285 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLocation, _ = time.LoadLocation("Europe/Berlin")
+
+func setupHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreateProjectDir(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustSaveTasks(t *testing.T, filename string, tasks []*Task) {
+	t.Helper()
+	if err := SaveTasks(filename, tasks); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testTask(project string, start time.Time, d time.Duration) *Task {
+	return &Task{
+		Project:  project,
+		Language: "Go",
+		Start:    start,
+		End:      start.Add(d),
+	}
+}
+
+func TestSaveTasksTruncates(t *testing.T) {
+	setupHome(t)
+
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	tasks := []*Task{
+		testTask("first", start, time.Hour),
+		testTask("second", start.Add(2*time.Hour), time.Hour),
+	}
+
+	mustSaveTasks(t, "tasks.csv", tasks)
+	mustSaveTasks(t, "tasks.csv", tasks[:1])
+
+	loaded, err := LoadTasks("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(loaded))
+	}
+
+	if loaded[0].Project != "first" {
+		t.Errorf("expected %q, got %q", "first", loaded[0].Project)
+	}
+}
+
+func TestBackupRotation(t *testing.T) {
+	setupHome(t)
+
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	tasks := []*Task{testTask("first", start, time.Hour)}
+
+	for range MAX_BACKUPS + 3 {
+		mustSaveTasks(t, "tasks.csv", tasks)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	backups, err := ListBackups("tasks.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != MAX_BACKUPS {
+		t.Fatalf("expected %d backups, got %d", MAX_BACKUPS, len(backups))
+	}
+
+	for i := 1; i < len(backups); i++ {
+		if backups[i].Created.After(backups[i-1].Created) {
+			t.Errorf("backups are not sorted newest first")
+		}
+	}
+
+	dir, _ := DefaultPath()
+	matches, _ := filepath.Glob(filepath.Join(dir, ".tasks.csv.tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files were left behind: %v", matches)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	setupHome(t)
+
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	mustSaveTasks(t, "tasks.csv", []*Task{testTask("old", start, time.Hour)})
+	time.Sleep(2 * time.Millisecond)
+	mustSaveTasks(t, "tasks.csv", []*Task{testTask("new", start, time.Hour)})
+
+	backups, err := ListBackups("tasks.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreBackup("tasks.csv", backups[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadTasks("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[0].Project != "old" {
+		t.Errorf("expected %q, got %q", "old", loaded[0].Project)
+	}
+}
+
+func TestRestoreOldestBackup(t *testing.T) {
+	setupHome(t)
+
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	// the first save has nothing to back up
+	for i := range MAX_BACKUPS + 1 {
+		mustSaveTasks(t, "tasks.csv", []*Task{testTask(fmt.Sprintf("save%d", i), start, time.Hour)})
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	backups, err := ListBackups("tasks.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != MAX_BACKUPS {
+		t.Fatalf("expected %d backups, got %d", MAX_BACKUPS, len(backups))
+	}
+
+	if err := RestoreBackup("tasks.csv", backups[len(backups)-1]); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadTasks("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[0].Project != "save0" {
+		t.Errorf("expected %q, got %q", "save0", loaded[0].Project)
+	}
+}
+
+func TestLoadTasksMalformedRow(t *testing.T) {
+	setupHome(t)
+
+	dir, _ := DefaultPath()
+	content := schemaLine(CSV_SCHEMA_VERSION) + "\n" +
+		"ID,Project,Language,Start,End,Tags,Note,Pauses\n" +
+		"a1b2c3,goalkeeper,Go,2024-10-01T09:00:00+02:00,2024-10-01T10:00:00+02:00,,,\n" +
+		"d4e5f6,goalkeeper,Go,yesterday,TBD,,,\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks("tasks.csv", testLocation)
+	if len(tasks) != 1 {
+		t.Errorf("expected the valid row to be loaded, got %d tasks", len(tasks))
+	}
+
+	if !errors.Is(err, ErrMalformedRow) {
+		t.Fatalf("expected ErrMalformedRow, got %v", err)
+	}
+
+	var rowErr *MalformedRowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("expected *MalformedRowError, got %T", err)
+	}
+
+	if rowErr.Line != 4 {
+		t.Errorf("expected line 4, got %d", rowErr.Line)
+	}
+
+	if rowErr.Field != "Start" {
+		t.Errorf("expected field %q, got %q", "Start", rowErr.Field)
+	}
+}
+
+func TestQuarantineRows(t *testing.T) {
+	setupHome(t)
+
+	dir, _ := DefaultPath()
+	content := schemaLine(CSV_SCHEMA_VERSION) + "\n" +
+		"ID,Project,Language,Start,End,Tags,Note,Pauses\n" +
+		"a1b2c3,goalkeeper,Go,2024-10-01T09:00:00+02:00,2024-10-01T10:00:00+02:00,,,\n" +
+		"d4e5f6,goalkeeper,Go,yesterday,TBD,,,\n" +
+		"only,two\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped, err := QuarantineRows("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(skipped.Rows) != 2 {
+		t.Fatalf("expected 2 quarantined rows, got %d", len(skipped.Rows))
+	}
+
+	quarantine, err := os.ReadFile(filepath.Join(dir, DEFAULT_QUARANTINE_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "d4e5f6,goalkeeper,Go,yesterday,TBD,,,\nonly,two\n"
+	if string(quarantine) != expected {
+		t.Errorf("expected quarantine file %q, got %q", expected, quarantine)
+	}
+
+	tasks, err := LoadTasks("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatalf("expected a clean task file, got %v", err)
+	}
+
+	if len(tasks) != 1 {
+		t.Errorf("expected 1 task, got %d", len(tasks))
+	}
+}
+
+func TestLoadTasksTimezones(t *testing.T) {
+	setupHome(t)
+
+	dir, _ := DefaultPath()
+	content := "Category,Title,Start,End\n" +
+		"legacy,Go,2024-10-01 09:00:00,2024-10-01 10:00:00\n" +
+		"offset,Go,2024-10-01T20:00:00-04:00,TBD\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks("tasks.csv", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	if !tasks[0].Start.Equal(legacy) {
+		t.Errorf("expected legacy row to start at %s, got %s", legacy, tasks[0].Start)
+	}
+
+	// 20:00 in New York is 02:00 on the next day in Berlin
+	offset := time.Date(2024, 10, 2, 2, 0, 0, 0, testLocation)
+	if !tasks[1].Start.Equal(offset) {
+		t.Errorf("expected row with offset to start at %s, got %s", offset, tasks[1].Start)
+	}
+
+	if tasks[1].Start.Location() != testLocation {
+		t.Errorf("expected start in %s, got %s", testLocation, tasks[1].Start.Location())
+	}
+
+	if got := GetTasksForDate(tasks, legacy); len(got) != 1 {
+		t.Errorf("expected 1 task on %s, got %d", legacy.Format(DateFormat), len(got))
+	}
+
+	mustSaveTasks(t, "tasks.csv", tasks)
+
+	saved, err := os.ReadFile(filepath.Join(dir, "tasks.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(saved), "2024-10-01T09:00:00+02:00") {
+		t.Errorf("expected legacy row to be saved with offset, got:\n%s", saved)
+	}
+}
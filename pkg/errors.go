@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMalformedRow is matched by every *MalformedRowError.
+	ErrMalformedRow  = errors.New("malformed row")
+	ErrConfigInvalid = errors.New("invalid config")
+	ErrInvalidRange  = errors.New("start must be before end")
+	ErrInvalidPeriod = errors.New("invalid period")
+	// ErrOverlap is matched by every *OverlapError.
+	ErrOverlap       = errors.New("tasks overlap")
+	ErrNotRunning    = errors.New("task is not running")
+	ErrAlreadyPaused = errors.New("task is already paused")
+	ErrNotPaused     = errors.New("task is not paused")
+)
+
+// MalformedRowError describes a row of the task file that could not be
+// turned into a task.
+type MalformedRowError struct {
+	// Line is the line of the row in the task file, 0 if unknown.
+	Line int
+	// Field is the name of the offending column.
+	Field string
+	Value string
+	// Raw is the row as it appears in the task file.
+	Raw string
+	Err error
+}
+
+func (e *MalformedRowError) Error() string {
+	msg := fmt.Sprintf("invalid %s %q: %v", e.Field, e.Value, e.Err)
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+func (e *MalformedRowError) Is(target error) bool {
+	return target == ErrMalformedRow
+}
+
+func (e *MalformedRowError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedRowsError is returned together with the tasks that could be loaded
+// when some rows of the task file were malformed and left out.
+type SkippedRowsError struct {
+	Filename string
+	Rows     []*MalformedRowError
+}
+
+func (e *SkippedRowsError) Error() string {
+	rows := "rows"
+	if len(e.Rows) == 1 {
+		rows = "row"
+	}
+	return fmt.Sprintf("skipped %d malformed %s in %s", len(e.Rows), rows, e.Filename)
+}
+
+func (e *SkippedRowsError) Unwrap() []error {
+	errs := make([]error, len(e.Rows))
+	for i, r := range e.Rows {
+		errs[i] = r
+	}
+	return errs
+}
+
+func (e *SkippedRowsError) rows() []*MalformedRowError {
+	if e == nil {
+		return nil
+	}
+	return e.Rows
+}
+
+// OverlapError is returned when a task would share time with another task.
+type OverlapError struct {
+	Task  *Task
+	Other *Task
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("%s - %s overlaps task %s (%s, %s - %s)",
+		e.Task.Start.Format(DateTimeFormat), FormatTimeOrTBD(e.Task.End, DateTimeFormat),
+		e.Other.ID, e.Other.Project,
+		e.Other.Start.Format(DateTimeFormat), FormatTimeOrTBD(e.Other.End, DateTimeFormat))
+}
+
+func (e *OverlapError) Is(target error) bool {
+	return target == ErrOverlap
+}
@@ -0,0 +1,314 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	GOAL_DAILY   = "daily"
+	GOAL_WEEKLY  = "weekly"
+	GOAL_MONTHLY = "monthly"
+	GOAL_YEARLY  = "yearly"
+)
+
+// Goal is a target amount of time for every period of a kind, like 25 hours
+// a week.
+type Goal struct {
+	Name   string
+	Target time.Duration
+	// Period returns the period of the goal that contains t.
+	Period func(t time.Time) Period
+}
+
+// day returns the first day of the change in loc.
+func (c GoalChange) day(loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation(DateFormat, c.From, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid from %q, use YYYY-MM-DD", c.From)
+	}
+	return day, nil
+}
+
+// At returns the goals that were in force on the day of t, following the
+// changes in History.
+func (g GoalsSection) At(t time.Time) GoalsSection {
+	changes := slices.Clone(g.History)
+	slices.SortStableFunc(changes, func(a, b GoalChange) int {
+		return strings.Compare(a.From, b.From)
+	})
+
+	day := StartOfDay(t)
+	for _, c := range changes {
+		from, err := c.day(t.Location())
+		if err != nil || from.After(day) {
+			continue
+		}
+
+		for _, field := range []struct {
+			value  *int
+			target *int
+		}{
+			{c.Daily, &g.Daily},
+			{c.Weekly, &g.Weekly},
+			{c.Monthly, &g.Monthly},
+			{c.Yearly, &g.Yearly},
+		} {
+			if field.value != nil {
+				*field.target = *field.value
+			}
+		}
+	}
+
+	return g
+}
+
+// SetGoal records that goal, one of GOAL_DAILY to GOAL_YEARLY, is minutes
+// from day on. A change that already starts on day is updated.
+func (g *GoalsSection) SetGoal(goal string, minutes int, day time.Time) error {
+	if minutes < 0 {
+		return fmt.Errorf("%w: a goal must not be negative", ErrConfigInvalid)
+	}
+
+	from := day.Format(DateFormat)
+	idx := slices.IndexFunc(g.History, func(c GoalChange) bool { return c.From == from })
+	if idx == -1 {
+		g.History = append(g.History, GoalChange{From: from})
+		idx = len(g.History) - 1
+	}
+	c := &g.History[idx]
+
+	switch goal {
+	case GOAL_DAILY:
+		c.Daily = &minutes
+	case GOAL_WEEKLY:
+		c.Weekly = &minutes
+	case GOAL_MONTHLY:
+		c.Monthly = &minutes
+	case GOAL_YEARLY:
+		c.Yearly = &minutes
+	default:
+		return fmt.Errorf("unknown goal %q, use %s, %s, %s or %s",
+			goal, GOAL_DAILY, GOAL_WEEKLY, GOAL_MONTHLY, GOAL_YEARLY)
+	}
+
+	slices.SortStableFunc(g.History, func(a, b GoalChange) int {
+		return strings.Compare(a.From, b.From)
+	})
+	return nil
+}
+
+// goalPeriods returns the kinds of goal periods from the shortest to the
+// longest. Weeks follow calendar.
+func goalPeriods(calendar Calendar) []Goal {
+	return []Goal{
+		{Name: GOAL_DAILY, Period: Day},
+		{Name: GOAL_WEEKLY, Period: calendar.Week},
+		{Name: GOAL_MONTHLY, Period: Month},
+		{Name: GOAL_YEARLY, Period: Year},
+	}
+}
+
+// Goals returns the goals that are set, from the shortest period to the
+// longest.
+func (g GoalsSection) Goals(calendar Calendar) []Goal {
+	targets := []int{g.Daily, g.Weekly, g.Monthly, g.Yearly}
+
+	goals := []Goal{}
+	for i, goal := range goalPeriods(calendar) {
+		goal.Target = minutes(targets[i])
+		if goal.Target > 0 {
+			goals = append(goals, goal)
+		}
+	}
+	return goals
+}
+
+func minutes(n int) time.Duration {
+	return time.Duration(n) * time.Minute
+}
+
+// Progress is the time tracked towards a goal in one of its periods.
+type Progress struct {
+	Goal   Goal
+	Period Period
+	Done   time.Duration
+	// DaysLeft is the number of work days left in the period, including
+	// the current day.
+	DaysLeft int
+}
+
+// NewProgress sums up the tasks that started in period, one of the periods
+// of goal. The work days left are counted from the day of now on.
+func NewProgress(goal Goal, period Period, tasks []*Task, calendar Calendar, now time.Time) Progress {
+	p := Progress{Goal: goal, Period: period}
+
+	for _, t := range tasks {
+		if period.Contains(t.Start) {
+			p.Done += t.Duration()
+		}
+	}
+
+	left := Period{From: StartOfDay(now), To: period.To}
+	if left.From.Before(period.From) {
+		left.From = period.From
+	}
+	if left.From.Before(left.To) {
+		p.DaysLeft = calendar.WorkDaysIn(left)
+	}
+
+	return p
+}
+
+// Ratio returns the part of the goal that is done, it can be above 1.
+func (p Progress) Ratio() float64 {
+	return float64(p.Done) / float64(p.Goal.Target)
+}
+
+// Remaining returns the time that is still missing to reach the goal.
+func (p Progress) Remaining() time.Duration {
+	return max(p.Goal.Target-p.Done, 0)
+}
+
+// PerDay returns the time to track on each of the work days left to reach
+// the goal in time. Without work days left it is everything that remains.
+func (p Progress) PerDay() time.Duration {
+	if p.DaysLeft == 0 {
+		return p.Remaining()
+	}
+	return (p.Remaining() / time.Duration(p.DaysLeft)).Round(time.Minute)
+}
+
+// TotalDuration adds up the durations of tasks.
+func TotalDuration(tasks []*Task) time.Duration {
+	var total time.Duration
+	for _, t := range tasks {
+		total += t.Duration()
+	}
+	return total
+}
+
+// Limit bounds the time spent in a period, in minutes. 0 leaves that side
+// open.
+type Limit struct {
+	Min int `toml:"min,omitzero"`
+	Max int `toml:"max,omitzero"`
+}
+
+// Budget holds the limits of a project or language, like at least 5 hours a
+// week or at most 10 hours a month:
+//
+//	[goals.projects.rewrite]
+//	weekly = { min = 300 }
+type Budget struct {
+	Daily   Limit `toml:"daily,omitempty"`
+	Weekly  Limit `toml:"weekly,omitempty"`
+	Monthly Limit `toml:"monthly,omitempty"`
+	Yearly  Limit `toml:"yearly,omitempty"`
+}
+
+func (b Budget) limits() []Limit {
+	return []Limit{b.Daily, b.Weekly, b.Monthly, b.Yearly}
+}
+
+func (b Budget) validate() error {
+	for i, limit := range b.limits() {
+		name := goalPeriods(DefaultCalendar)[i].Name
+		if limit.Min < 0 || limit.Max < 0 {
+			return fmt.Errorf("%s limits must not be negative", name)
+		}
+		if limit.Max > 0 && limit.Min > limit.Max {
+			return fmt.Errorf("%s min %d is above max %d", name, limit.Min, limit.Max)
+		}
+	}
+	return nil
+}
+
+// BudgetCheck compares the time a project or language got in a period with
+// its limit.
+type BudgetCheck struct {
+	// Name is the project or language.
+	Name string
+	// Kind is the kind of period, like GOAL_WEEKLY.
+	Kind     string
+	Period   Period
+	Min, Max time.Duration
+	Done     time.Duration
+}
+
+// Check returns a BudgetCheck for every limit of the budget, from the shortest
+// period to the longest. The periods are the ones holding ref and only tasks
+// that match count. Daily minimums do not apply on rest days.
+func (b Budget) Check(name string, match func(*Task) bool, tasks []*Task, calendar Calendar, ref time.Time) []BudgetCheck {
+	checks := []BudgetCheck{}
+
+	for i, limit := range b.limits() {
+		kind := goalPeriods(calendar)[i]
+
+		// there is nothing to reach on rest days
+		if kind.Name == GOAL_DAILY && !calendar.IsWorkDay(ref) {
+			limit.Min = 0
+		}
+
+		if limit.Min == 0 && limit.Max == 0 {
+			continue
+		}
+
+		c := BudgetCheck{
+			Name:   name,
+			Kind:   kind.Name,
+			Period: kind.Period(ref),
+			Min:    minutes(limit.Min),
+			Max:    minutes(limit.Max),
+		}
+
+		for _, t := range tasks {
+			if match(t) && c.Period.Contains(t.Start) {
+				c.Done += t.Duration()
+			}
+		}
+
+		checks = append(checks, c)
+	}
+
+	return checks
+}
+
+// Over returns how much the time is above the maximum.
+func (c BudgetCheck) Over() time.Duration {
+	if c.Max == 0 {
+		return 0
+	}
+	return max(c.Done-c.Max, 0)
+}
+
+// Missing returns how much time is still needed to reach the minimum.
+func (c BudgetCheck) Missing() time.Duration {
+	return max(c.Min-c.Done, 0)
+}
+
+// GoalSpan returns the smallest period holding every kind of goal period
+// that contains ref, so a single load covers the progress of all goals.
+func GoalSpan(calendar Calendar, ref time.Time) Period {
+	var periods []Period
+	for _, kind := range goalPeriods(calendar) {
+		periods = append(periods, kind.Period(ref))
+	}
+	return Span(periods...)
+}
+
+// Span returns the smallest period holding all of the bounded periods.
+func Span(periods ...Period) Period {
+	var span Period
+	for i, p := range periods {
+		if i == 0 || p.From.Before(span.From) {
+			span.From = p.From
+		}
+		if i == 0 || p.To.After(span.To) {
+			span.To = p.To
+		}
+	}
+	return span
+}
@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	// Wednesday 2024-10-02
+	now := time.Date(2024, 10, 2, 12, 0, 0, 0, testLocation)
+	monday := time.Date(2024, 9, 30, 9, 0, 0, 0, testLocation)
+
+	tasks := []*Task{
+		testTask("goalkeeper", monday.AddDate(0, 0, -1), 3*time.Hour),
+		testTask("goalkeeper", monday, 4*time.Hour),
+		testTask("website", monday.AddDate(0, 0, 1), 2*time.Hour),
+	}
+
+	goals := GoalsSection{Weekly: 20 * 60, Monthly: 0}.Goals(DefaultCalendar)
+	if len(goals) != 1 || goals[0].Name != GOAL_WEEKLY {
+		t.Fatalf("expected only the weekly goal, got %v", goals)
+	}
+
+	goal := goals[0]
+	p := NewProgress(goal, goal.Period(now), tasks, DefaultCalendar, now)
+
+	if p.Done != 6*time.Hour {
+		t.Errorf("expected 6h done this week, got %s", p.Done)
+	}
+	// Wednesday to Friday
+	if p.DaysLeft != 3 {
+		t.Errorf("expected 3 work days left, got %d", p.DaysLeft)
+	}
+	if p.Remaining() != 14*time.Hour {
+		t.Errorf("expected 14h remaining, got %s", p.Remaining())
+	}
+	if want := 4*time.Hour + 40*time.Minute; p.PerDay() != want {
+		t.Errorf("expected %s per day, got %s", want, p.PerDay())
+	}
+
+	// the previous week is over
+	p = NewProgress(goal, goal.Period(now.AddDate(0, 0, -7)), tasks, DefaultCalendar, now)
+	if p.DaysLeft != 0 || p.Done != 3*time.Hour {
+		t.Errorf("expected 3h done and no days left last week, got %s and %d days", p.Done, p.DaysLeft)
+	}
+}
+
+func TestBudgetCheck(t *testing.T) {
+	// Sunday 2024-10-06, a rest day
+	ref := time.Date(2024, 10, 6, 12, 0, 0, 0, testLocation)
+	monday := time.Date(2024, 9, 30, 9, 0, 0, 0, testLocation)
+
+	tasks := []*Task{
+		testTask("maintenance", monday, 4*time.Hour),
+		testTask("maintenance", monday.AddDate(0, 0, 1), 3*time.Hour),
+		testTask("rewrite", monday.AddDate(0, 0, 2), time.Hour),
+	}
+
+	budget := Budget{
+		Daily:  Limit{Min: 30},
+		Weekly: Limit{Min: 60, Max: 6 * 60},
+	}
+
+	checks := budget.Check("maintenance", func(t *Task) bool { return t.Project == "maintenance" },
+		tasks, DefaultCalendar, ref)
+
+	if len(checks) != 1 {
+		t.Fatalf("expected only the weekly check on a rest day, got %d", len(checks))
+	}
+	if c := checks[0]; c.Done != 7*time.Hour || c.Over() != time.Hour || c.Missing() != 0 {
+		t.Errorf("expected 7h done and 1h over budget, got %s done and %s over", c.Done, c.Over())
+	}
+
+	if err := (Budget{Weekly: Limit{Min: 120, Max: 60}}).validate(); err == nil {
+		t.Error("expected an error for a minimum above the maximum")
+	}
+}
+
+func TestGoalHistory(t *testing.T) {
+	setupHome(t)
+
+	doc := DefaultTomlConfig()
+	doc.GoalsSection.Daily = 60
+	doc.GoalsSection.Weekly = 600
+	if err := CreateTomlFile(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 12, 0, 0, 0, testLocation)
+	}
+
+	if err := SaveGoal(GOAL_DAILY, 120, day(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGoal(GOAL_WEEKLY, 300, day(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGoal(GOAL_DAILY, 90, day(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveGoal("hourly", 1, day(10)); err == nil {
+		t.Error("expected an error for an unknown goal")
+	}
+
+	doc, err := LoadTomlConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(doc.GoalsSection.History); n != 2 {
+		t.Fatalf("expected 2 changes, got %d", n)
+	}
+
+	tests := []struct {
+		day           time.Time
+		daily, weekly int
+	}{
+		{day(1), 60, 600},
+		{day(5), 60, 300},
+		{day(10), 90, 300},
+		{day(31), 90, 300},
+	}
+
+	for _, tt := range tests {
+		goals := doc.GoalsSection.At(tt.day)
+		if goals.Daily != tt.daily || goals.Weekly != tt.weekly {
+			t.Errorf("%s: expected daily %d and weekly %d, got %d and %d",
+				tt.day.Format(DateFormat), tt.daily, tt.weekly, goals.Daily, goals.Weekly)
+		}
+	}
+}
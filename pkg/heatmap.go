@@ -0,0 +1,56 @@
+package pkg
+
+import "time"
+
+// HEAT_LEVELS is the number of levels returned by HeatLevel.
+const HEAT_LEVELS = 5
+
+// heatFallbackGoal is used to bucket days without a daily goal.
+const heatFallbackGoal = 2 * time.Hour
+
+// HeatLevel buckets the time of a day relative to the daily goal: 0 for
+// nothing, 1 for less than half the goal, 2 for less than the goal, 3 for the
+// goal and 4 for one and a half times the goal or more. Days without a goal
+// are compared with two hours.
+func HeatLevel(total, goal time.Duration) int {
+	if goal <= 0 {
+		goal = heatFallbackGoal
+	}
+
+	switch {
+	case total <= 0:
+		return 0
+	case 2*total < goal:
+		return 1
+	case total < goal:
+		return 2
+	case 2*total < 3*goal:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// HeatmapCell returns the row and the column of day in a grid that starts on
+// first, with a column for every week and a row for every weekday.
+func HeatmapCell(first, day time.Time) (row, col int) {
+	offset := daysBetween(first, day)
+	return offset % 7, offset / 7
+}
+
+// HeatmapWeeks returns the number of columns needed to show the days from
+// first to last in a grid that starts on first.
+func HeatmapWeeks(first, last time.Time) int {
+	_, col := HeatmapCell(first, last)
+	return col + 1
+}
+
+// daysBetween counts the calendar days from a to b. It does not divide by 24
+// hours, as days of a daylight saving time change are 23 or 25 hours long.
+func daysBetween(a, b time.Time) int {
+	date := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	return int(date(b).Sub(date(a)).Hours() / 24)
+}
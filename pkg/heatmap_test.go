@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatLevel(t *testing.T) {
+	tests := []struct {
+		total, goal time.Duration
+		want        int
+	}{
+		{0, time.Hour, 0},
+		{20 * time.Minute, time.Hour, 1},
+		{30 * time.Minute, time.Hour, 2},
+		{time.Hour, time.Hour, 3},
+		{89 * time.Minute, time.Hour, 3},
+		{90 * time.Minute, time.Hour, 4},
+		// without a goal two hours are the reference
+		{time.Hour, 0, 2},
+		{3 * time.Hour, 0, 4},
+	}
+
+	for _, tt := range tests {
+		if got := HeatLevel(tt.total, tt.goal); got != tt.want {
+			t.Errorf("HeatLevel(%s, %s) = %d, expected %d", tt.total, tt.goal, got, tt.want)
+		}
+	}
+}
+
+func TestHeatmapCellDST(t *testing.T) {
+	tests := []struct {
+		zone  string
+		first time.Time
+		last  time.Time
+	}{
+		// summer time makes both years an hour short of 52 weeks, which
+		// must not cost the last column
+		{"Europe/Berlin", time.Date(2020, 10, 26, 0, 0, 0, 0, time.UTC), time.Date(2021, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC), time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		loc, err := time.LoadLocation(tt.zone)
+		if err != nil {
+			t.Skipf("time zone %s is not available: %v", tt.zone, err)
+		}
+		first := time.Date(tt.first.Year(), tt.first.Month(), tt.first.Day(), 0, 0, 0, 0, loc)
+		last := time.Date(tt.last.Year(), tt.last.Month(), tt.last.Day(), 0, 0, 0, 0, loc)
+
+		weeks := HeatmapWeeks(first, last)
+		if weeks != 53 {
+			t.Errorf("%s: expected 53 weeks, got %d", tt.zone, weeks)
+		}
+
+		for day, i := first, 0; !day.After(last); day, i = day.AddDate(0, 0, 1), i+1 {
+			row, col := HeatmapCell(first, day)
+			if row != i%7 || col != i/7 || col >= weeks {
+				t.Errorf("%s: expected %s in row %d and column %d of %d, got %d and %d",
+					tt.zone, day.Format(DateFormat), i%7, i/7, weeks, row, col)
+				break
+			}
+		}
+	}
+}
+
+func TestHeatmapWeeksLeapYear(t *testing.T) {
+	// 2024 begins on a Monday, the last day of a week that starts on Tuesday
+	calendar := DefaultCalendar
+	calendar.WeekStart = time.Tuesday
+
+	year := Year(time.Date(2024, 1, 1, 0, 0, 0, 0, testLocation))
+	first := calendar.Week(year.From).From
+	if got := HeatmapWeeks(first, year.LastDay()); got != 54 {
+		t.Errorf("expected 54 weeks, got %d", got)
+	}
+
+	calendar.WeekStart = time.Monday
+	first = calendar.Week(year.From).From
+	if got := HeatmapWeeks(first, year.LastDay()); got != 53 {
+		t.Errorf("expected 53 weeks, got %d", got)
+	}
+}
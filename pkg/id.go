@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// ID_LENGTH is the number of characters of a task ID.
+const ID_LENGTH = 6
+
+func randomID() string {
+	b := make([]byte, ID_LENGTH/2)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// idSet hands out random task IDs that are unique among the IDs it knows.
+type idSet map[string]bool
+
+func newIDSet(tasks ...*Task) idSet {
+	ids := idSet{}
+	for _, t := range tasks {
+		ids[t.ID] = true
+	}
+	return ids
+}
+
+func (ids idSet) next() string {
+	for {
+		id := randomID()
+		if !ids[id] {
+			ids[id] = true
+			return id
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+const JOURNAL_EXT = ".journal"
+
+const (
+	OpStart  = "start"
+	OpEnd    = "end"
+	OpAdd    = "add"
+	OpEdit   = "edit"
+	OpDelete = "delete"
+	OpPause  = "pause"
+	OpResume = "resume"
+	OpSwitch = "switch"
+	OpBatch  = "batch"
+	OpUndo   = "undo"
+	OpRedo   = "redo"
+	OpSkip   = "skip"
+
+	// the task file was replaced as a whole, the changes before can no longer
+	// be undone or redone
+	OpRestore    = "restore"
+	OpQuarantine = "quarantine"
+	OpMigrate    = "migration"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrEntrySkipped is returned when an entry no longer fits the stored
+	// tasks. It is skipped, so the entry before it can be undone next.
+	ErrEntrySkipped = errors.New("the change was skipped")
+)
+
+// JournalEntry is one line of the journal. Undo and redo entries refer to
+// the entry they revert or repeat by its Seq.
+type JournalEntry struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`
+	Target  int       `json:"target,omitempty"`
+	Changes []Change  `json:"changes"`
+}
+
+// Journal is an append-only log of all changes made to the stored tasks,
+// one JSON entry per line.
+type Journal struct {
+	path string
+}
+
+// JournalFor returns the journal kept next to the data file filename.
+func JournalFor(filename string) (*Journal, error) {
+	path, err := dataPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	return journalAt(path), nil
+}
+
+// journalAt returns the journal of the data file at path.
+func journalAt(path string) *Journal {
+	return &Journal{path: path + JOURNAL_EXT}
+}
+
+// checkpoint records that op replaced the tasks of the data file at path
+// without going through the journal. Without a journal there is nothing to
+// record.
+func checkpoint(path, op string) error {
+	journal := journalAt(path)
+	if _, err := os.Stat(journal.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if _, err := journal.Record(op, 0, nil); err != nil {
+		return fmt.Errorf("error recording %s in the journal: %w", op, err)
+	}
+	return nil
+}
+
+// Entries returns all entries of the journal, oldest first.
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []JournalEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error reading journal line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Record appends an entry for op to the journal.
+func (j *Journal) Record(op string, target int, changes []Change) (JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	entry := JournalEntry{
+		Seq:     len(entries) + 1,
+		Time:    time.Now(),
+		Op:      op,
+		Target:  target,
+		Changes: changes,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("error opening journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return JournalEntry{}, fmt.Errorf("error writing journal: %w", err)
+	}
+
+	return entry, f.Sync()
+}
+
+// stacks replays the journal and returns the entries that can be undone and
+// redone, the next one last. last is the most recent entry that replaced the
+// tasks as a whole, nil if there is none.
+func (j *Journal) stacks() (done, undone []JournalEntry, last *JournalEntry, err error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	bySeq := make(map[int]JournalEntry, len(entries))
+	for _, e := range entries {
+		bySeq[e.Seq] = e
+	}
+
+	remove := func(stack []JournalEntry, seq int) []JournalEntry {
+		return slices.DeleteFunc(stack, func(e JournalEntry) bool { return e.Seq == seq })
+	}
+
+	for _, e := range entries {
+		switch e.Op {
+		case OpUndo:
+			done = remove(done, e.Target)
+			undone = append(undone, bySeq[e.Target])
+		case OpRedo:
+			undone = remove(undone, e.Target)
+			done = append(done, bySeq[e.Target])
+		case OpSkip:
+			done = remove(done, e.Target)
+			undone = remove(undone, e.Target)
+		case OpRestore, OpQuarantine, OpMigrate:
+			done, undone = nil, nil
+			last = &e
+		default:
+			done = append(done, e)
+			undone = undone[:0]
+		}
+	}
+
+	return done, undone, last, nil
+}
+
+// JournaledStore records every change made through it in a journal, so the
+// changes can be undone and redone step by step.
+type JournaledStore struct {
+	Store
+	journal *Journal
+}
+
+func NewJournaledStore(store Store, journal *Journal) *JournaledStore {
+	return &JournaledStore{Store: store, journal: journal}
+}
+
+func (s *JournaledStore) Append(task *Task) error {
+	return s.Apply(Change{After: task})
+}
+
+func (s *JournaledStore) Update(task *Task) error {
+	before, err := s.Store.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before, After: task})
+}
+
+func (s *JournaledStore) Delete(task *Task) error {
+	before, err := s.Store.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before})
+}
+
+// Apply stores the changes and records them in the journal. Before should
+// hold the complete stored task, so the change can be reverted.
+func (s *JournaledStore) Apply(changes ...Change) error {
+	return s.record(describeChanges(changes), 0, changes)
+}
+
+// ApplyAs is Apply for commands that know the name of their operation, so it
+// is not guessed from the changes.
+func (s *JournaledStore) ApplyAs(op string, changes ...Change) error {
+	return s.record(op, 0, changes)
+}
+
+// record stores the changes and records them as op. The changes are stored
+// first, as new tasks only get their ID then. If recording fails they are
+// reverted, so no change is kept that can not be undone.
+func (s *JournaledStore) record(op string, target int, changes []Change) error {
+	if err := s.Store.Apply(changes...); err != nil {
+		return err
+	}
+
+	if _, err := s.journal.Record(op, target, changes); err != nil {
+		if revertErr := s.Store.Apply(inverse(changes)...); revertErr != nil {
+			return fmt.Errorf("error recording %s: %w, reverting it failed too: %v", op, err, revertErr)
+		}
+		return fmt.Errorf("error recording %s, nothing was changed: %w", op, err)
+	}
+	return nil
+}
+
+// inverse returns the changes that revert changes, in the order to apply
+// them.
+func inverse(changes []Change) []Change {
+	reverted := make([]Change, 0, len(changes))
+	for _, c := range slices.Backward(changes) {
+		reverted = append(reverted, c.Inverse())
+	}
+	return reverted
+}
+
+// Undo reverts the most recent change that has not been undone yet and
+// returns the entry that was reverted.
+func (s *JournaledStore) Undo() (JournalEntry, error) {
+	done, _, last, err := s.journal.stacks()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	if len(done) == 0 {
+		return JournalEntry{}, replacedSince(ErrNothingToUndo, last)
+	}
+	entry := done[len(done)-1]
+
+	if err := s.record(OpUndo, entry.Seq, inverse(entry.Changes)); err != nil {
+		return entry, s.skip(entry, fmt.Errorf("error undoing %s: %w", entry.Op, err))
+	}
+	return entry, nil
+}
+
+// Redo repeats the most recently undone change and returns its entry.
+func (s *JournaledStore) Redo() (JournalEntry, error) {
+	_, undone, last, err := s.journal.stacks()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	if len(undone) == 0 {
+		return JournalEntry{}, replacedSince(ErrNothingToRedo, last)
+	}
+	entry := undone[len(undone)-1]
+
+	if err := s.record(OpRedo, entry.Seq, entry.Changes); err != nil {
+		return entry, s.skip(entry, fmt.Errorf("error redoing %s: %w", entry.Op, err))
+	}
+	return entry, nil
+}
+
+// skip drops entry from undo and redo when err shows that it no longer fits
+// the stored tasks, e.g. after they were edited by hand. Otherwise the same
+// entry would fail again on every try.
+func (s *JournaledStore) skip(entry JournalEntry, err error) error {
+	if !errors.Is(err, ErrTaskNotFound) && !errors.Is(err, ErrTaskExists) && !errors.Is(err, ErrTaskChanged) {
+		return err
+	}
+
+	if _, recordErr := s.journal.Record(OpSkip, entry.Seq, nil); recordErr != nil {
+		return fmt.Errorf("%w, skipping it failed: %v", err, recordErr)
+	}
+	return fmt.Errorf("%w (%w)", err, ErrEntrySkipped)
+}
+
+// replacedSince adds to err when the tasks were last replaced as a whole.
+func replacedSince(err error, last *JournalEntry) error {
+	if last == nil {
+		return err
+	}
+	return fmt.Errorf("%w since the %s at %s", err, last.Op, last.Time.Format(DateTimeFormat))
+}
+
+// describeChanges names the operation that made changes.
+func describeChanges(changes []Change) string {
+	if len(changes) == 2 &&
+		describeChanges(changes[:1]) == OpEnd && describeChanges(changes[1:]) == OpStart {
+		return OpSwitch
+	}
+	if len(changes) != 1 {
+		return OpBatch
+	}
+
+	c := changes[0]
+	switch {
+	case c.Before == nil && c.After.IsFinished():
+		return OpAdd
+	case c.Before == nil:
+		return OpStart
+	case c.After == nil:
+		return OpDelete
+	case !c.Before.IsFinished() && c.After.IsFinished() &&
+		c.Before.Project == c.After.Project && c.Before.Language == c.After.Language &&
+		c.Before.Start.Equal(c.After.Start):
+		return OpEnd
+	case !c.Before.IsPaused() && c.After.IsPaused():
+		return OpPause
+	case c.Before.IsPaused() && !c.After.IsPaused() && !c.After.IsFinished():
+		return OpResume
+	default:
+		return OpEdit
+	}
+}
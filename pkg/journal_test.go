@@ -0,0 +1,267 @@
+package pkg
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJournaledStoreUndoRedo(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			journal, err := JournalFor(name + ".journal-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewJournaledStore(store, journal)
+
+			day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			task := NewTask("goalkeeper", "Go", day)
+			if err := s.Append(task); err != nil {
+				t.Fatal(err)
+			}
+
+			ended := *task
+			ended.End = day.Add(time.Hour)
+			if err := s.Update(&ended); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Delete(&ended); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := journal.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ops []string
+			for _, e := range entries {
+				ops = append(ops, e.Op)
+			}
+			if len(ops) != 3 || ops[0] != OpStart || ops[1] != OpEnd || ops[2] != OpDelete {
+				t.Fatalf("expected start, end, delete in the journal, got %v", ops)
+			}
+
+			// undo the delete and the end
+			for _, op := range []string{OpDelete, OpEnd} {
+				entry, err := s.Undo()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if entry.Op != op {
+					t.Errorf("expected to undo %s, got %s", op, entry.Op)
+				}
+			}
+
+			got, err := s.Get(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.IsFinished() {
+				t.Errorf("expected task to be running again after undoing end, ends at %s", got.End)
+			}
+
+			if _, err := s.Redo(); err != nil {
+				t.Fatal(err)
+			}
+			got, err = s.Get(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.End.Equal(ended.End) {
+				t.Errorf("expected redo to end task at %s, got %s", ended.End, got.End)
+			}
+
+			// a new change drops the remaining redo
+			if err := s.Append(testTask("website", day.Add(2*time.Hour), time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Redo(); !errors.Is(err, ErrNothingToRedo) {
+				t.Errorf("expected ErrNothingToRedo, got %v", err)
+			}
+
+			for range 3 {
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := s.Undo(); !errors.Is(err, ErrNothingToUndo) {
+				t.Errorf("expected ErrNothingToUndo, got %v", err)
+			}
+
+			tasks, err := s.Query(TaskFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 0 {
+				t.Errorf("expected no tasks after undoing everything, got %d", len(tasks))
+			}
+		})
+	}
+}
+
+func TestJournaledStoreRecordFails(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			journal, err := JournalFor(name + ".journal-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a directory in place of the journal can not be written to
+			if err := os.MkdirAll(journal.path, 0744); err != nil {
+				t.Fatal(err)
+			}
+			s := NewJournaledStore(store, journal)
+
+			day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			if err := s.Append(testTask("goalkeeper", day, time.Hour)); err == nil {
+				t.Fatal("expected an error when the journal can not be written")
+			}
+
+			tasks, err := s.Query(TaskFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 0 {
+				t.Errorf("expected the change to be reverted, got %d tasks", len(tasks))
+			}
+		})
+	}
+}
+
+func TestJournaledStoreSkip(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			journal, err := JournalFor(name + ".journal-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewJournaledStore(store, journal)
+
+			day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			if err := s.Append(testTask("goalkeeper", day, time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			task := testTask("website", day.Add(2*time.Hour), time.Hour)
+			if err := s.Append(task); err != nil {
+				t.Fatal(err)
+			}
+
+			// changed without the journal, e.g. by hand
+			edited := *task
+			edited.Note = "edited by hand"
+			if err := store.Update(&edited); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.Undo(); !errors.Is(err, ErrEntrySkipped) || !errors.Is(err, ErrTaskChanged) {
+				t.Fatalf("expected the changed task to be skipped, got %v", err)
+			}
+			if _, err := s.Get(task.ID); err != nil {
+				t.Errorf("expected the changed task to be kept, got %v", err)
+			}
+
+			entry, err := s.Undo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry.Seq != 1 {
+				t.Errorf("expected to undo the first entry next, got %d", entry.Seq)
+			}
+		})
+	}
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+	setupHome(t)
+
+	journal, err := JournalFor("tasks.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewJournaledStore(NewCSVStore("tasks.csv", testLocation), journal)
+
+	day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	for _, project := range []string{"goalkeeper", "website"} {
+		if err := s.Append(testTask(project, day, time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		day = day.Add(2 * time.Hour)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	backups, err := ListBackups("tasks.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreBackup("tasks.csv", backups[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected nothing to undo after a restore, got %v", err)
+	}
+
+	if err := s.Append(testTask("docs", day, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := s.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Changes[0].After.Project != "docs" {
+		t.Errorf("expected to undo adding docs, got %+v", entry.Changes[0].After)
+	}
+}
+
+func TestJournaledStoreSwitch(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			journal, err := JournalFor(name + ".journal-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewJournaledStore(store, journal)
+
+			day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			running := NewTask("goalkeeper", "Go", day)
+			if err := s.Append(running); err != nil {
+				t.Fatal(err)
+			}
+
+			ended, next := running.Switch("website", "TypeScript", day.Add(time.Hour+500*time.Millisecond))
+			if running.IsFinished() {
+				t.Error("expected the running task to be left unchanged")
+			}
+			if !next.Start.Equal(ended.End) {
+				t.Errorf("expected the next task to start at %s, got %s", ended.End, next.Start)
+			}
+
+			if err := s.ApplyAs(OpSwitch, Change{Before: running, After: ended}, Change{After: next}); err != nil {
+				t.Fatal(err)
+			}
+
+			entry, err := s.Undo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry.Op != OpSwitch {
+				t.Errorf("expected to undo %s, got %s", OpSwitch, entry.Op)
+			}
+
+			last, err := s.Last()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last.ID != running.ID || last.IsFinished() {
+				t.Errorf("expected %s to be running again, got %s", running.ID, last)
+			}
+
+			// a delete together with a start is no switch
+			if op := describeChanges([]Change{{Before: running}, {After: next}}); op != OpBatch {
+				t.Errorf("expected a delete and a start to be a %s, got %s", OpBatch, op)
+			}
+		})
+	}
+}
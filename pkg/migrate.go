@@ -0,0 +1,268 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV_SCHEMA_VERSION is the layout of the task file written by SaveTasks.
+// It is stored in the first line of the file, files without it are version 1.
+const CSV_SCHEMA_VERSION = 5
+
+const schemaPrefix = "# goalkeeper schema "
+
+// csvColumns are the column names of the current task file layout.
+var csvColumns = []string{"ID", "Project", "Language", "Start", "End", "Tags", "Note", "Pauses"}
+
+// csvMigration upgrades a task file by one version.
+type csvMigration struct {
+	Description string
+	// Header returns the column names of the new version.
+	Header func(header []string) []string
+	// Rows converts all rows of the old version into the new one. It must
+	// return the same number of rows in the same order.
+	Rows func(rows [][]string) ([][]string, error)
+}
+
+// csvMigrations[i] upgrades a task file from version i+1 to i+2.
+var csvMigrations = []csvMigration{
+	{
+		Description: "rename the columns Category and Title to Project and Language",
+		Header: func(header []string) []string {
+			return []string{"Project", "Language", "Start", "End"}
+		},
+		Rows: func(rows [][]string) ([][]string, error) {
+			return rows, nil
+		},
+	},
+	{
+		Description: "add a stable ID to every task",
+		Header: func(header []string) []string {
+			return append([]string{"ID"}, header...)
+		},
+		Rows: func(rows [][]string) ([][]string, error) {
+			ids := newIDSet()
+			for i, row := range rows {
+				rows[i] = append([]string{ids.next()}, row...)
+			}
+			return rows, nil
+		},
+	},
+	{
+		Description: "add tags and a note to every task",
+		Header: func(header []string) []string {
+			return append(header, "Tags", "Note")
+		},
+		Rows: func(rows [][]string) ([][]string, error) {
+			for i, row := range rows {
+				rows[i] = append(row, "", "")
+			}
+			return rows, nil
+		},
+	},
+	{
+		Description: "add the breaks of every task",
+		Header: func(header []string) []string {
+			return append(header, "Pauses")
+		},
+		Rows: func(rows [][]string) ([][]string, error) {
+			for i, row := range rows {
+				rows[i] = append(row, "")
+			}
+			return rows, nil
+		},
+	},
+}
+
+// schemaLine returns the first line of a task file in the given version.
+func schemaLine(version int) string {
+	return schemaPrefix + strconv.Itoa(version)
+}
+
+// splitSchemaLine returns the schema version of a task file and its content
+// without the schema line. lines is the number of lines that were removed.
+func splitSchemaLine(data []byte) (version int, body []byte, lines int, err error) {
+	if !bytes.HasPrefix(data, []byte(schemaPrefix)) {
+		return 1, data, 0, nil
+	}
+
+	first, rest, _ := bytes.Cut(data, []byte("\n"))
+	value := strings.TrimSpace(strings.TrimPrefix(string(first), schemaPrefix))
+
+	version, err = strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, nil, 0, fmt.Errorf("invalid schema version %q", value)
+	}
+
+	return version, rest, 1, nil
+}
+
+// migrateCSV upgrades the content of the task file at path to
+// CSV_SCHEMA_VERSION. If a migration is necessary, the old file is kept in the
+// backup directory and the upgraded content is written back to path.
+func migrateCSV(path string, data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+
+	version, body, _, err := splitSchemaLine(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	if version > CSV_SCHEMA_VERSION {
+		return nil, fmt.Errorf("%s uses schema version %d, this goalkeeper only knows up to %d, please update",
+			filepath.Base(path), version, CSV_SCHEMA_VERSION)
+	}
+
+	if version == CSV_SCHEMA_VERSION {
+		return data, nil
+	}
+
+	for v := version; v < CSV_SCHEMA_VERSION; v++ {
+		body, err = applyCSVMigration(body, csvMigrations[v-1])
+		if err != nil {
+			return nil, fmt.Errorf("error migrating %s to schema version %d: %w", filepath.Base(path), v+1, err)
+		}
+	}
+
+	migrated := append([]byte(schemaLine(CSV_SCHEMA_VERSION)+"\n"), body...)
+
+	backup, err := backupBeforeMigration(path, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(migrated)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := checkpoint(path, OpMigrate); err != nil {
+		return nil, err
+	}
+
+	log.Printf("migrated %s from schema version %d to %d, the old file is kept at %s\n",
+		filepath.Base(path), version, CSV_SCHEMA_VERSION, backup)
+
+	return migrated, nil
+}
+
+// applyCSVMigration runs migration on the rows of body. Rows that are not
+// valid csv are copied unchanged, so they can still be quarantined later.
+func applyCSVMigration(body []byte, migration csvMigration) ([]byte, error) {
+	lines := strings.Split(string(body), "\n")
+
+	// every entry is either a parsed row or the raw text of a malformed one
+	type entry struct {
+		row int
+		raw []string
+	}
+
+	var (
+		header  []string
+		rows    [][]string
+		entries []entry
+	)
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			entries = append(entries, entry{row: -1, raw: lines[parseErr.StartLine-1 : parseErr.Line]})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header == nil {
+			header = record
+			continue
+		}
+
+		entries = append(entries, entry{row: len(rows)})
+		rows = append(rows, record)
+	}
+
+	migrated, err := migration.Rows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrated) != len(rows) {
+		return nil, fmt.Errorf("migration returned %d rows, expected %d", len(migrated), len(rows))
+	}
+
+	out := new(bytes.Buffer)
+	writer := csv.NewWriter(out)
+
+	if err := writer.Write(migration.Header(header)); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.row == -1 {
+			writer.Flush()
+			for _, line := range e.raw {
+				fmt.Fprintln(out, strings.TrimRight(line, "\r"))
+			}
+			continue
+		}
+
+		if err := writer.Write(migrated[e.row]); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return out.Bytes(), writer.Error()
+}
+
+// migrationBackupPath returns where the file at path is kept before it is
+// migrated from version. Unlike the backups of regular saves, these are never
+// rotated out.
+func migrationBackupPath(path string, version int) (string, error) {
+	dir, err := BackupDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	base, ext := splitExt(filepath.Base(path))
+	name := fmt.Sprintf("%s.v%d-%s%s", base, version, time.Now().Format(backupTimeFormat), ext)
+	return filepath.Join(dir, name), nil
+}
+
+func backupBeforeMigration(path string, version int) (string, error) {
+	backup, err := migrationBackupPath(path, version)
+	if err != nil {
+		return "", err
+	}
+
+	if err := copyFile(path, backup); err != nil {
+		return "", fmt.Errorf("error backing up %s before migration: %w", path, err)
+	}
+
+	return backup, nil
+}
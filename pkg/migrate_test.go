@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateCSV(t *testing.T) {
+	setupHome(t)
+
+	dir, _ := DefaultPath()
+	legacy := "Category,Title,Start,End\n" +
+		"goalkeeper,Go,2024-10-01 09:00:00,2024-10-01 10:00:00\n" +
+		"\"broken,row\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks.csv"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks("tasks.csv", testLocation)
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task after migration, got %d (%v)", len(tasks), err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if lines[0] != schemaLine(CSV_SCHEMA_VERSION) {
+		t.Errorf("expected schema line %q, got %q", schemaLine(CSV_SCHEMA_VERSION), lines[0])
+	}
+
+	if lines[1] != strings.Join(csvColumns, ",") {
+		t.Errorf("expected header %q, got %q", strings.Join(csvColumns, ","), lines[1])
+	}
+
+	if lines[3] != "\"broken,row" {
+		t.Errorf("expected malformed row to be kept, got %q", lines[3])
+	}
+
+	backupDir, _ := BackupDir()
+	backups, _ := filepath.Glob(filepath.Join(backupDir, "tasks.v1-*.csv"))
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 pre-migration backup, got %d", len(backups))
+	}
+
+	backup, err := os.ReadFile(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(backup) != legacy {
+		t.Errorf("expected backup to contain the original file, got %q", backup)
+	}
+}
+
+func TestMigrateCSVNewerVersion(t *testing.T) {
+	setupHome(t)
+
+	dir, _ := DefaultPath()
+	content := schemaLine(CSV_SCHEMA_VERSION+1) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "tasks.csv"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadTasks("tasks.csv", testLocation); err == nil {
+		t.Error("expected an error for a file from a newer version")
+	}
+}
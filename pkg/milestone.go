@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Milestone is a long-term goal for the total time spent on a project or
+// language, like 100 hours of Rust:
+//
+//	[[goals.milestones]]
+//	name = "100 hours of Rust"
+//	language = "Rust"
+//	hours = 100
+//	deadline = "2025-06-30"
+type Milestone struct {
+	Name string `toml:"name"`
+	// Project and Language select the tasks that count, all tasks count if
+	// both are empty.
+	Project  string  `toml:"project,omitempty"`
+	Language string  `toml:"language,omitempty"`
+	Hours    float64 `toml:"hours"`
+	// Deadline is the optional last day as "YYYY-MM-DD".
+	Deadline string `toml:"deadline,omitempty"`
+}
+
+func (m Milestone) validate() error {
+	if m.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if m.Hours <= 0 {
+		return fmt.Errorf("hours of %q must be positive", m.Name)
+	}
+	if _, err := m.deadline(time.UTC); err != nil {
+		return fmt.Errorf("%q: %w", m.Name, err)
+	}
+	return nil
+}
+
+// deadline returns the end of the deadline day in loc, zero without a
+// deadline.
+func (m Milestone) deadline(loc *time.Location) (time.Time, error) {
+	if m.Deadline == "" {
+		return time.Time{}, nil
+	}
+
+	day, err := time.ParseInLocation(DateFormat, m.Deadline, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid deadline %q, use YYYY-MM-DD", m.Deadline)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// Match reports whether t counts towards the milestone.
+func (m Milestone) Match(t *Task) bool {
+	return (m.Project == "" || t.Project == m.Project) &&
+		(m.Language == "" || t.Language == m.Language)
+}
+
+// Target returns the total time of the milestone.
+func (m Milestone) Target() time.Duration {
+	return time.Duration(m.Hours * float64(time.Hour))
+}
+
+// MilestoneProgress is how far a milestone has come.
+type MilestoneProgress struct {
+	Milestone Milestone
+	Done      time.Duration
+	// Start is the day of the first task that counts.
+	Start time.Time
+	// Pace is the average time per day since Start.
+	Pace time.Duration
+	// Projected is the day the target is reached at the current pace, zero
+	// without a pace or once the target is reached.
+	Projected time.Time
+	// Deadline is the end of the deadline day, zero without a deadline.
+	Deadline time.Time
+}
+
+// NewMilestoneProgress adds up the matching tasks up to now.
+func NewMilestoneProgress(m Milestone, tasks []*Task, now time.Time) MilestoneProgress {
+	p := MilestoneProgress{Milestone: m}
+	p.Deadline, _ = m.deadline(now.Location())
+
+	for _, t := range tasks {
+		if !m.Match(t) {
+			continue
+		}
+		p.Done += t.Duration()
+		if day := StartOfDay(t.Start.In(now.Location())); p.Start.IsZero() || day.Before(p.Start) {
+			p.Start = day
+		}
+	}
+
+	if p.Start.IsZero() {
+		return p
+	}
+
+	days := Period{From: p.Start, To: StartOfDay(now).AddDate(0, 0, 1)}.Days()
+	p.Pace = p.Done / time.Duration(days)
+
+	if p.Pace > 0 && p.Remaining() > 0 {
+		daysLeft := int((p.Remaining() + p.Pace - 1) / p.Pace)
+		p.Projected = StartOfDay(now).AddDate(0, 0, daysLeft)
+	}
+
+	return p
+}
+
+// Ratio returns the part of the target that is done.
+func (p MilestoneProgress) Ratio() float64 {
+	return float64(p.Done) / float64(p.Milestone.Target())
+}
+
+// Remaining returns the time still missing to reach the target.
+func (p MilestoneProgress) Remaining() time.Duration {
+	return max(p.Milestone.Target()-p.Done, 0)
+}
+
+// Needed returns the time per day needed from today on to make the deadline,
+// 0 without a deadline or once it has passed.
+func (p MilestoneProgress) Needed(now time.Time) time.Duration {
+	if p.Deadline.IsZero() || !p.Deadline.After(now) {
+		return 0
+	}
+
+	days := Period{From: StartOfDay(now), To: p.Deadline}.Days()
+	return p.Remaining() / time.Duration(days)
+}
+
+// OnTrack reports whether the target is reached by the deadline at the
+// current pace. Milestones without a deadline are always on track.
+func (p MilestoneProgress) OnTrack() bool {
+	if p.Remaining() == 0 || p.Deadline.IsZero() {
+		return true
+	}
+	return !p.Projected.IsZero() && p.Projected.Before(p.Deadline)
+}
@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMilestoneProgress(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 9, 0, 0, 0, testLocation)
+	}
+
+	tasks := []*Task{
+		testTask("goalkeeper", day(1), 2*time.Hour),
+		testTask("goalkeeper", day(3), 2*time.Hour),
+		{Project: "website", Language: "TypeScript", Start: day(4), End: day(4).Add(5 * time.Hour)},
+	}
+	for _, task := range tasks[:2] {
+		task.Language = "Rust"
+	}
+
+	m := Milestone{Name: "10 hours of Rust", Language: "Rust", Hours: 10, Deadline: "2024-10-09"}
+	if err := m.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := day(4)
+	p := NewMilestoneProgress(m, tasks, now)
+
+	if p.Done != 4*time.Hour {
+		t.Errorf("expected 4h done, got %s", p.Done)
+	}
+	// 4h over the 4 days from the 1st to the 4th
+	if p.Pace != time.Hour {
+		t.Errorf("expected a pace of 1h per day, got %s", p.Pace)
+	}
+	if want := time.Date(2024, 10, 10, 0, 0, 0, 0, testLocation); !p.Projected.Equal(want) {
+		t.Errorf("expected to reach the milestone on %s, got %s", want, p.Projected)
+	}
+	if p.OnTrack() {
+		t.Error("expected to miss the deadline at the current pace")
+	}
+	// 6h left over the 6 days from the 4th to the 9th
+	if p.Needed(now) != time.Hour {
+		t.Errorf("expected 1h needed per day, got %s", p.Needed(now))
+	}
+
+	if err := (Milestone{Name: "broken", Hours: 1, Deadline: "soon"}).validate(); err == nil {
+		t.Error("expected an error for an invalid deadline")
+	}
+}
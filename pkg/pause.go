@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Pause is a break during a task. End is zero while the break lasts.
+type Pause struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the length of the break, an ongoing break lasts until now.
+func (p Pause) Duration() time.Duration {
+	end := p.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(p.Start)
+}
+
+// IsPaused reports whether the task is running but on a break.
+func (t Task) IsPaused() bool {
+	return !t.IsFinished() && len(t.Pauses) > 0 && t.Pauses[len(t.Pauses)-1].End.IsZero()
+}
+
+// Pause starts a break at at. Only a running task can be paused.
+func (t *Task) Pause(at time.Time) error {
+	switch {
+	case t.IsFinished():
+		return ErrNotRunning
+	case t.IsPaused():
+		return ErrAlreadyPaused
+	}
+
+	at = at.In(t.Start.Location()).Truncate(time.Second)
+	if last := t.lastBreakEnd(); at.Before(last) {
+		return fmt.Errorf("%w: the break can not begin before %s", ErrInvalidRange, last.Format(DateTimeFormat))
+	}
+
+	t.Pauses = append(t.Pauses, Pause{Start: at})
+	return nil
+}
+
+// Resume ends the current break at at.
+func (t *Task) Resume(at time.Time) error {
+	if !t.IsPaused() {
+		return ErrNotPaused
+	}
+
+	pause := &t.Pauses[len(t.Pauses)-1]
+	at = at.In(t.Start.Location()).Truncate(time.Second)
+	if !at.After(pause.Start) {
+		return fmt.Errorf("%w: the break began at %s", ErrInvalidRange, pause.Start.Format(DateTimeFormat))
+	}
+
+	pause.End = at
+	return nil
+}
+
+// BreakDuration returns the time spent on breaks.
+func (t Task) BreakDuration() time.Duration {
+	var total time.Duration
+	for _, p := range t.Pauses {
+		total += p.Duration()
+	}
+	return total
+}
+
+// lastBreakEnd returns when the last break ended, or the start of the task.
+func (t Task) lastBreakEnd() time.Time {
+	if len(t.Pauses) == 0 {
+		return t.Start
+	}
+	return t.Pauses[len(t.Pauses)-1].End
+}
+
+// validatePauses reports breaks that are not in order or not within the task.
+func (t Task) validatePauses() error {
+	last := t.Start
+	for i, p := range t.Pauses {
+		open := p.End.IsZero()
+		switch {
+		case p.Start.Before(last):
+			return fmt.Errorf("%w: break %d begins at %s, before %s", ErrInvalidRange,
+				i+1, p.Start.Format(DateTimeFormat), last.Format(DateTimeFormat))
+		case open && (i != len(t.Pauses)-1 || t.IsFinished()):
+			return fmt.Errorf("%w: break %d has no end", ErrInvalidRange, i+1)
+		case !open && !p.Start.Before(p.End):
+			return fmt.Errorf("%w: break %d does not end after it begins", ErrInvalidRange, i+1)
+		case !open && t.IsFinished() && p.End.After(t.End):
+			return fmt.Errorf("%w: break %d ends after the task", ErrInvalidRange, i+1)
+		}
+		last = p.End
+	}
+	return nil
+}
+
+// formatPauses stores breaks as "start/end" intervals separated by spaces.
+// Ongoing breaks end in "TBD".
+func formatPauses(pauses []Pause) string {
+	intervals := make([]string, len(pauses))
+	for i, p := range pauses {
+		intervals[i] = p.Start.Format(time.RFC3339) + "/" + FormatTimeOrTBD(p.End, time.RFC3339)
+	}
+	return strings.Join(intervals, " ")
+}
+
+// parsePauses reads breaks written by formatPauses.
+func parsePauses(value string, loc *time.Location) ([]Pause, error) {
+	var pauses []Pause
+	for _, interval := range strings.Fields(value) {
+		start, end, ok := strings.Cut(interval, "/")
+		if !ok {
+			return nil, fmt.Errorf("break %q is not of the form start/end", interval)
+		}
+
+		var (
+			p   Pause
+			err error
+		)
+		if p.Start, err = parseTimestamp(start, loc); err != nil {
+			return nil, err
+		}
+		if end != "TBD" {
+			if p.End, err = parseTimestamp(end, loc); err != nil {
+				return nil, err
+			}
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, nil
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	task := NewTask("goalkeeper", "Go", start)
+
+	if err := task.Resume(start.Add(time.Minute)); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("expected ErrNotPaused, got %v", err)
+	}
+
+	if err := task.Pause(start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !task.IsPaused() {
+		t.Error("expected the task to be paused")
+	}
+	if err := task.Pause(start.Add(2 * time.Hour)); !errors.Is(err, ErrAlreadyPaused) {
+		t.Errorf("expected ErrAlreadyPaused, got %v", err)
+	}
+	if err := task.Resume(start.Add(time.Hour)); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for an empty break, got %v", err)
+	}
+	if err := task.Resume(start.Add(90 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.Pause(start.Add(80 * time.Minute)); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for a break during the last one, got %v", err)
+	}
+
+	// a break that is still going on ends with the task
+	if err := task.Pause(start.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	task.FinishAt(start.Add(3 * time.Hour))
+
+	if task.IsPaused() {
+		t.Error("expected a finished task not to be paused")
+	}
+	if task.BreakDuration() != 90*time.Minute {
+		t.Errorf("expected 1h 30m of breaks, got %s", task.BreakDuration())
+	}
+	if task.Duration() != 90*time.Minute {
+		t.Errorf("expected 1h 30m of work, got %s", task.Duration())
+	}
+	if err := task.Validate(); err != nil {
+		t.Errorf("expected a valid task, got %v", err)
+	}
+	if err := task.Pause(start.Add(4 * time.Hour)); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+
+	task.End = start.Add(150 * time.Minute)
+	if err := task.Validate(); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected a break after the end to be invalid, got %v", err)
+	}
+}
+
+func TestParsePauses(t *testing.T) {
+	start := time.Date(2024, 10, 1, 12, 0, 0, 0, testLocation)
+	pauses := []Pause{
+		{Start: start, End: start.Add(30 * time.Minute)},
+		{Start: start.Add(time.Hour)},
+	}
+
+	parsed, err := parsePauses(formatPauses(pauses), testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 || !parsed[0].End.Equal(pauses[0].End) || !parsed[1].End.IsZero() {
+		t.Errorf("expected %v, got %v", pauses, parsed)
+	}
+
+	if _, err := parsePauses("12:00", testLocation); err == nil {
+		t.Error("expected an error for a break without an end")
+	}
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is a range of calendar days. From is the start of the first day and
+// To the start of the day after the last one. A zero From or To leaves that
+// side open.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// AllTime is the period without bounds.
+var AllTime = Period{}
+
+// Day returns the period of the day of t.
+func Day(t time.Time) Period {
+	start := StartOfDay(t)
+	return Period{From: start, To: start.AddDate(0, 0, 1)}
+}
+
+// Week returns the week of t that begins on the weekday start.
+func Week(t time.Time, start time.Weekday) Period {
+	day := StartOfDay(t)
+	first := day.AddDate(0, 0, -(int(day.Weekday())-int(start)+7)%7)
+	return Period{From: first, To: first.AddDate(0, 0, 7)}
+}
+
+// ISOWeek returns week number week of the ISO 8601 year year in loc. ISO
+// weeks always begin on Monday.
+func ISOWeek(year, week int, loc *time.Location) (Period, error) {
+	// the 4th of January is always in week 1
+	p := Week(time.Date(year, time.January, 4, 0, 0, 0, 0, loc), time.Monday)
+	p = p.Shift(week - 1)
+
+	if y, w := p.From.ISOWeek(); y != year || w != week {
+		return Period{}, fmt.Errorf("%w: %d has no week %d", ErrInvalidPeriod, year, week)
+	}
+	return p, nil
+}
+
+// Month returns the calendar month of t.
+func Month(t time.Time) Period {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Period{From: first, To: first.AddDate(0, 1, 0)}
+}
+
+// Year returns the calendar year of t.
+func Year(t time.Time) Period {
+	first := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	return Period{From: first, To: first.AddDate(1, 0, 0)}
+}
+
+// DayRange returns the period from the day of from up to and including the
+// day of to. A zero from or to leaves that side open.
+func DayRange(from, to time.Time) (Period, error) {
+	var p Period
+	if !from.IsZero() {
+		p.From = StartOfDay(from)
+	}
+	if !to.IsZero() {
+		p.To = StartOfDay(to).AddDate(0, 0, 1)
+	}
+
+	if p.IsBounded() && !p.From.Before(p.To) {
+		return Period{}, fmt.Errorf("%w: %s is after %s",
+			ErrInvalidRange, from.Format(DateFormat), to.Format(DateFormat))
+	}
+	return p, nil
+}
+
+// Shift moves the period by n times its own length. Months and years keep
+// to the calendar, so shifting February by one returns March.
+func (p Period) Shift(n int) Period {
+	switch {
+	case !p.IsBounded():
+		return p
+	case p.Equal(Month(p.From)):
+		return Month(p.From.AddDate(0, n, 0))
+	case p.Equal(Year(p.From)):
+		return Year(p.From.AddDate(n, 0, 0))
+	default:
+		days := p.Days()
+		return Period{From: p.From.AddDate(0, 0, n*days), To: p.To.AddDate(0, 0, n*days)}
+	}
+}
+
+// Equal reports whether both periods cover the same time.
+func (p Period) Equal(other Period) bool {
+	return p.From.Equal(other.From) && p.To.Equal(other.To)
+}
+
+// IsBounded reports whether the period has a start and an end.
+func (p Period) IsBounded() bool {
+	return !p.From.IsZero() && !p.To.IsZero()
+}
+
+// Contains reports whether t falls into the period.
+func (p Period) Contains(t time.Time) bool {
+	return (p.From.IsZero() || !t.Before(p.From)) && (p.To.IsZero() || t.Before(p.To))
+}
+
+// Days returns the number of calendar days of a bounded period.
+func (p Period) Days() int {
+	days := 0
+	for d := p.From; d.Before(p.To); d = d.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// LastDay returns the start of the last day of a period with an end.
+func (p Period) LastDay() time.Time {
+	return p.To.AddDate(0, 0, -1)
+}
+
+func (p Period) String() string {
+	switch {
+	case p.Equal(AllTime):
+		return "all time"
+	case p.From.IsZero():
+		return "until " + p.LastDay().Format(DateFormat)
+	case p.To.IsZero():
+		return "since " + p.From.Format(DateFormat)
+	case p.Days() == 1:
+		return p.From.Format(DateFormat)
+	default:
+		return p.From.Format(DateFormat) + " - " + p.LastDay().Format(DateFormat)
+	}
+}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPeriods(t *testing.T) {
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, testLocation)
+	}
+	// a Sunday, the last day of its week
+	ref := time.Date(2024, 3, 31, 18, 0, 0, 0, testLocation)
+
+	tests := []struct {
+		name     string
+		got      Period
+		from, to time.Time
+	}{
+		{"day", Day(ref), day(2024, 3, 31), day(2024, 4, 1)},
+		{"week", Week(ref, time.Monday), day(2024, 3, 25), day(2024, 4, 1)},
+		{"previous week", Week(ref, time.Monday).Shift(-1), day(2024, 3, 18), day(2024, 3, 25)},
+		{"week from Sunday", Week(ref, time.Sunday), day(2024, 3, 31), day(2024, 4, 7)},
+		{"week from Saturday", Week(ref, time.Saturday), day(2024, 3, 30), day(2024, 4, 6)},
+		{"month", Month(ref), day(2024, 3, 1), day(2024, 4, 1)},
+		{"previous month", Month(ref).Shift(-1), day(2024, 2, 1), day(2024, 3, 1)},
+		{"next month", Month(day(2024, 1, 31)).Shift(1), day(2024, 2, 1), day(2024, 3, 1)},
+		{"year", Year(ref), day(2024, 1, 1), day(2025, 1, 1)},
+		{"previous year", Year(ref).Shift(-1), day(2023, 1, 1), day(2024, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		if !tt.got.From.Equal(tt.from) || !tt.got.To.Equal(tt.to) {
+			t.Errorf("%s: got %s to %s, expected %s to %s", tt.name, tt.got.From, tt.got.To, tt.from, tt.to)
+		}
+	}
+
+	// the switch to daylight saving time makes this week an hour shorter
+	if days := Week(ref, time.Monday).Days(); days != 7 {
+		t.Errorf("expected 7 days in the week, got %d", days)
+	}
+}
+
+func TestISOWeek(t *testing.T) {
+	// 2026-01-01 is a Thursday, so week 1 starts in December
+	p, err := ISOWeek(2026, 1, testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 12, 29, 0, 0, 0, 0, testLocation); !p.From.Equal(want) {
+		t.Errorf("expected week 1 of 2026 to start on %s, got %s", want, p.From)
+	}
+
+	if _, err := ISOWeek(2026, 53, testLocation); err != nil {
+		t.Errorf("expected 2026 to have 53 weeks: %v", err)
+	}
+	if _, err := ISOWeek(2025, 53, testLocation); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expected ErrInvalidPeriod for week 53 of 2025, got %v", err)
+	}
+}
+
+func TestDayRange(t *testing.T) {
+	from := time.Date(2024, 10, 1, 15, 0, 0, 0, testLocation)
+	to := time.Date(2024, 10, 3, 9, 0, 0, 0, testLocation)
+
+	p, err := DayRange(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Days() != 3 || !p.Contains(to) || p.Contains(to.AddDate(0, 0, 1)) {
+		t.Errorf("expected 2024-10-01 to 2024-10-03, got %s", p)
+	}
+
+	if _, err := DayRange(to, from); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange, got %v", err)
+	}
+
+	open, err := DayRange(time.Time{}, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !open.Contains(time.Date(1990, 1, 1, 0, 0, 0, 0, testLocation)) {
+		t.Errorf("expected a range without start to contain old days")
+	}
+}
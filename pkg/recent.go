@@ -0,0 +1,32 @@
+package pkg
+
+import "sort"
+
+// RecentTasks returns the latest task of each of the n most recently used
+// project and language pairs, the most recent first. n <= 0 returns all
+// pairs.
+func RecentTasks(tasks []*Task, n int) []*Task {
+	sorted := make([]*Task, len(tasks))
+	copy(sorted, tasks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.After(sorted[j].Start)
+	})
+
+	type pair struct{ project, language string }
+	seen := make(map[pair]bool)
+
+	var recent []*Task
+	for _, t := range sorted {
+		p := pair{t.Project, t.Language}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		recent = append(recent, t)
+		if len(recent) == n {
+			break
+		}
+	}
+	return recent
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecentTasks(t *testing.T) {
+	day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+
+	website := testTask("website", day.Add(2*time.Hour), time.Hour)
+	website.Language = "TypeScript"
+	latest := testTask("goalkeeper", day.AddDate(0, 0, 1), time.Hour)
+
+	tasks := []*Task{
+		testTask("goalkeeper", day, time.Hour),
+		website,
+		latest,
+		testTask("dotfiles", day.Add(-time.Hour), 30*time.Minute),
+	}
+
+	recent := RecentTasks(tasks, 0)
+	if len(recent) != 3 {
+		t.Fatalf("expected 3 distinct pairs, got %d", len(recent))
+	}
+	if recent[0] != latest || recent[1] != website || recent[2].Project != "dotfiles" {
+		t.Errorf("expected goalkeeper, website and dotfiles, got %v", recent)
+	}
+
+	if recent := RecentTasks(tasks, 2); len(recent) != 2 {
+		t.Errorf("expected 2 pairs, got %d", len(recent))
+	}
+}
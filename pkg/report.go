@@ -0,0 +1,312 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats. OUTPUT_TABLE is the human readable default, the others are
+// meant for scripts and their keys do not change between versions.
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+	OUTPUT_CSV   = "csv"
+	OUTPUT_YAML  = "yaml"
+)
+
+// OutputFormats lists all valid output formats.
+var OutputFormats = []string{OUTPUT_TABLE, OUTPUT_JSON, OUTPUT_CSV, OUTPUT_YAML}
+
+// Report is data that can be written as JSON, YAML or CSV. CSV cannot hold
+// nested data, so a report decides which rows it is made of. The first row is
+// the header.
+type Report interface {
+	Records() [][]string
+}
+
+// WriteReport writes r to w in format, which is one of OUTPUT_JSON,
+// OUTPUT_CSV and OUTPUT_YAML.
+func WriteReport(w io.Writer, format string, r Report) error {
+	switch format {
+	case OUTPUT_JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case OUTPUT_YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	case OUTPUT_CSV:
+		return csv.NewWriter(w).WriteAll(r.Records())
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// TaskReport is a task with its duration, which leaves out its breaks. End is
+// null while the task is running, the duration then counts up to now. Paused
+// is true during a break.
+type TaskReport struct {
+	ID              string        `json:"id" yaml:"id"`
+	Project         string        `json:"project" yaml:"project"`
+	Language        string        `json:"language" yaml:"language"`
+	Start           time.Time     `json:"start" yaml:"start"`
+	End             *time.Time    `json:"end" yaml:"end"`
+	Running         bool          `json:"running" yaml:"running"`
+	Paused          bool          `json:"paused" yaml:"paused"`
+	DurationSeconds int64         `json:"duration_seconds" yaml:"duration_seconds"`
+	BreakSeconds    int64         `json:"break_seconds" yaml:"break_seconds"`
+	Breaks          []BreakReport `json:"breaks" yaml:"breaks"`
+	Tags            []string      `json:"tags" yaml:"tags"`
+	Note            string        `json:"note" yaml:"note"`
+}
+
+// BreakReport is a break of a task, End is null while it lasts.
+type BreakReport struct {
+	Start time.Time  `json:"start" yaml:"start"`
+	End   *time.Time `json:"end" yaml:"end"`
+}
+
+// taskReportHeader are the csv columns of a task, its tags are separated by
+// spaces.
+var taskReportHeader = []string{"id", "project", "language", "start", "end", "running", "paused",
+	"duration_seconds", "break_seconds", "tags", "note"}
+
+func NewTaskReport(t *Task) TaskReport {
+	r := TaskReport{
+		ID:              t.ID,
+		Project:         t.Project,
+		Language:        t.Language,
+		Start:           t.Start,
+		Running:         !t.IsFinished(),
+		Paused:          t.IsPaused(),
+		DurationSeconds: seconds(t.Duration()),
+		BreakSeconds:    seconds(t.BreakDuration()),
+		Breaks:          []BreakReport{},
+		Tags:            append([]string{}, t.Tags...),
+		Note:            t.Note,
+	}
+	r.End = optionalTime(t.End)
+
+	for _, p := range t.Pauses {
+		r.Breaks = append(r.Breaks, BreakReport{Start: p.Start, End: optionalTime(p.End)})
+	}
+	return r
+}
+
+// optionalTime returns nil for a zero t.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (r TaskReport) Records() [][]string {
+	return [][]string{taskReportHeader, r.record()}
+}
+
+func (r TaskReport) record() []string {
+	end := ""
+	if r.End != nil {
+		end = r.End.Format(time.RFC3339)
+	}
+	return []string{r.ID, r.Project, r.Language, r.Start.Format(time.RFC3339), end,
+		strconv.FormatBool(r.Running), strconv.FormatBool(r.Paused),
+		strconv.FormatInt(r.DurationSeconds, 10), strconv.FormatInt(r.BreakSeconds, 10),
+		strings.Join(r.Tags, " "), r.Note}
+}
+
+func newTaskReports(tasks []*Task) []TaskReport {
+	reports := make([]TaskReport, 0, len(tasks))
+	for _, t := range tasks {
+		reports = append(reports, NewTaskReport(t))
+	}
+	return reports
+}
+
+// GoalReport is the progress towards a goal. From and To are the days the
+// goal period begins and ends on, both included. Progress is the part of the
+// target that is done, it can be above 1.
+type GoalReport struct {
+	Goal             string  `json:"goal" yaml:"goal"`
+	From             string  `json:"from" yaml:"from"`
+	To               string  `json:"to" yaml:"to"`
+	TargetSeconds    int64   `json:"target_seconds" yaml:"target_seconds"`
+	DoneSeconds      int64   `json:"done_seconds" yaml:"done_seconds"`
+	RemainingSeconds int64   `json:"remaining_seconds" yaml:"remaining_seconds"`
+	Progress         float64 `json:"progress" yaml:"progress"`
+	WorkDaysLeft     int     `json:"work_days_left" yaml:"work_days_left"`
+}
+
+func NewGoalReport(p Progress) GoalReport {
+	return GoalReport{
+		Goal:             p.Goal.Name,
+		From:             p.Period.From.Format(DateFormat),
+		To:               p.Period.LastDay().Format(DateFormat),
+		TargetSeconds:    seconds(p.Goal.Target),
+		DoneSeconds:      seconds(p.Done),
+		RemainingSeconds: seconds(p.Remaining()),
+		Progress:         p.Ratio(),
+		WorkDaysLeft:     p.DaysLeft,
+	}
+}
+
+// DayReport holds the tasks of a single day with their total time and
+// breaks and the progress towards the daily goal, which is left out when
+// there is none. As CSV it holds the tasks.
+type DayReport struct {
+	Date         string       `json:"date" yaml:"date"`
+	Tasks        []TaskReport `json:"tasks" yaml:"tasks"`
+	TotalSeconds int64        `json:"total_seconds" yaml:"total_seconds"`
+	BreakSeconds int64        `json:"break_seconds" yaml:"break_seconds"`
+	Goal         *GoalReport  `json:"goal,omitempty" yaml:"goal,omitempty"`
+}
+
+// NewDayReport reports tasks, which started on the day of date. goal is the
+// daily goal in force on that day, zero if there is none.
+func NewDayReport(date time.Time, tasks []*Task, goal time.Duration, calendar Calendar, now time.Time) DayReport {
+	r := DayReport{
+		Date:  date.Format(DateFormat),
+		Tasks: newTaskReports(tasks),
+	}
+	for _, t := range r.Tasks {
+		r.TotalSeconds += t.DurationSeconds
+		r.BreakSeconds += t.BreakSeconds
+	}
+
+	if goal > 0 {
+		g := NewGoalReport(NewProgress(Goal{Name: GOAL_DAILY, Target: goal}, Day(date), tasks, calendar, now))
+		r.Goal = &g
+	}
+	return r
+}
+
+func (r DayReport) Records() [][]string {
+	records := [][]string{taskReportHeader}
+	for _, t := range r.Tasks {
+		records = append(records, t.record())
+	}
+	return records
+}
+
+// TotalReport is the time spent on a day, project, language or tag. Share is
+// its part of the total time of the report.
+type TotalReport struct {
+	Name            string  `json:"name" yaml:"name"`
+	DurationSeconds int64   `json:"duration_seconds" yaml:"duration_seconds"`
+	Share           float64 `json:"share" yaml:"share"`
+}
+
+// SummaryReport holds the tasks of a period, their totals by day, project,
+// language and tag and the progress towards the goals. A task counts towards
+// every one of its tags, so the shares of the tags can add up to more than 1.
+// From and To are the first and the last day of the period, they are empty
+// when that side is open. As CSV it holds the totals, with a kind column of
+// "day", "project", "language", "tag" and "total".
+type SummaryReport struct {
+	From         string        `json:"from" yaml:"from"`
+	To           string        `json:"to" yaml:"to"`
+	Tasks        []TaskReport  `json:"tasks" yaml:"tasks"`
+	Days         []TotalReport `json:"days" yaml:"days"`
+	Projects     []TotalReport `json:"projects" yaml:"projects"`
+	Languages    []TotalReport `json:"languages" yaml:"languages"`
+	Tags         []TotalReport `json:"tags" yaml:"tags"`
+	TotalSeconds int64         `json:"total_seconds" yaml:"total_seconds"`
+	Goals        []GoalReport  `json:"goals" yaml:"goals"`
+}
+
+// NewSummaryReport reports the tasks of period, totaled by day in loc. The
+// duration of every task is taken once, so running tasks add up to the total.
+func NewSummaryReport(period Period, tasks []*Task, progress []Progress, loc *time.Location) SummaryReport {
+	r := SummaryReport{
+		Tasks: newTaskReports(tasks),
+		Goals: []GoalReport{},
+	}
+	if !period.From.IsZero() {
+		r.From = period.From.Format(DateFormat)
+	}
+	if !period.To.IsZero() {
+		r.To = period.LastDay().Format(DateFormat)
+	}
+
+	for _, t := range r.Tasks {
+		r.TotalSeconds += t.DurationSeconds
+	}
+
+	r.Days = totals(r.Tasks, r.TotalSeconds, func(t TaskReport) []string {
+		return []string{StartOfDay(t.Start.In(loc)).Format(DateFormat)}
+	})
+	// days are listed in order, not by their duration
+	sort.Slice(r.Days, func(i, j int) bool { return r.Days[i].Name < r.Days[j].Name })
+
+	r.Projects = totals(r.Tasks, r.TotalSeconds, func(t TaskReport) []string { return []string{t.Project} })
+	r.Languages = totals(r.Tasks, r.TotalSeconds, func(t TaskReport) []string { return []string{t.Language} })
+	r.Tags = totals(r.Tasks, r.TotalSeconds, func(t TaskReport) []string { return t.Tags })
+
+	for _, p := range progress {
+		r.Goals = append(r.Goals, NewGoalReport(p))
+	}
+
+	return r
+}
+
+func (r SummaryReport) Records() [][]string {
+	records := [][]string{{"kind", "name", "duration_seconds", "share"}}
+	add := func(kind string, totals []TotalReport) {
+		for _, t := range totals {
+			records = append(records, []string{kind, t.Name,
+				strconv.FormatInt(t.DurationSeconds, 10), strconv.FormatFloat(t.Share, 'f', -1, 64)})
+		}
+	}
+
+	add("day", r.Days)
+	add("project", r.Projects)
+	add("language", r.Languages)
+	add("tag", r.Tags)
+	add("total", []TotalReport{{DurationSeconds: r.TotalSeconds, Share: 1}})
+	return records
+}
+
+// totals sums up tasks by the names returned by field, the longest first.
+func totals(tasks []TaskReport, total int64, field func(TaskReport) []string) []TotalReport {
+	durations := make(map[string]int64)
+	for _, t := range tasks {
+		for _, name := range field(t) {
+			durations[name] += t.DurationSeconds
+		}
+	}
+
+	reports := make([]TotalReport, 0, len(durations))
+	for name, d := range durations {
+		r := TotalReport{Name: name, DurationSeconds: d}
+		if total > 0 {
+			r.Share = float64(d) / float64(total)
+		}
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].DurationSeconds != reports[j].DurationSeconds {
+			return reports[i].DurationSeconds > reports[j].DurationSeconds
+		}
+		return reports[i].Name < reports[j].Name
+	})
+	return reports
+}
+
+// seconds returns d in whole seconds.
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
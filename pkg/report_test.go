@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSummaryReport(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 10, d, hour, 0, 0, 0, testLocation)
+	}
+
+	tasks := []*Task{
+		testTask("goalkeeper", day(1, 9), 2*time.Hour),
+		testTask("website", day(1, 14), time.Hour),
+		testTask("goalkeeper", day(2, 9), time.Hour),
+	}
+	tasks[1].Language = "TypeScript"
+
+	period := Week(day(1, 0), time.Monday)
+	progress := []Progress{NewProgress(Goal{Name: GOAL_WEEKLY, Target: 8 * time.Hour}, period, tasks, DefaultCalendar, day(2, 12))}
+
+	r := NewSummaryReport(period, tasks, progress, testLocation)
+
+	if r.From != "2024-09-30" || r.To != "2024-10-06" {
+		t.Errorf("expected the week from 2024-09-30 to 2024-10-06, got %s to %s", r.From, r.To)
+	}
+	if r.TotalSeconds != 4*3600 {
+		t.Errorf("expected a total of 4h, got %ds", r.TotalSeconds)
+	}
+	if len(r.Days) != 2 || r.Days[0].Name != "2024-10-01" || r.Days[0].DurationSeconds != 3*3600 {
+		t.Errorf("expected 3h on 2024-10-01 first, got %+v", r.Days)
+	}
+	if len(r.Projects) != 2 || r.Projects[0].Name != "goalkeeper" || r.Projects[0].Share != 0.75 {
+		t.Errorf("expected goalkeeper first with a share of 0.75, got %+v", r.Projects)
+	}
+	if len(r.Goals) != 1 || r.Goals[0].RemainingSeconds != 4*3600 || r.Goals[0].Progress != 0.5 {
+		t.Errorf("expected half of the weekly goal done, got %+v", r.Goals)
+	}
+
+	var out bytes.Buffer
+	if err := WriteReport(&out, OUTPUT_JSON, r); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"from", "to", "tasks", "days", "projects", "languages", "total_seconds", "goals"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("expected key %q in the json output", key)
+		}
+	}
+
+	out.Reset()
+	if err := WriteReport(&out, OUTPUT_CSV, r); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if lines[0] != "kind,name,duration_seconds,share" || lines[len(lines)-1] != "total,,14400,1" {
+		t.Errorf("unexpected csv output:\n%s", out.String())
+	}
+}
+
+func TestDayReport(t *testing.T) {
+	start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	running := NewTask("goalkeeper", "Go", start)
+	running.ID = "abc123"
+
+	r := NewDayReport(start, []*Task{running}, time.Hour, DefaultCalendar, start)
+
+	if r.Date != "2024-10-01" || r.Goal == nil || r.Goal.Goal != GOAL_DAILY {
+		t.Errorf("expected the daily goal of 2024-10-01, got %+v", r)
+	}
+
+	var out bytes.Buffer
+	if err := WriteReport(&out, OUTPUT_YAML, r); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Tasks []map[string]any `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Tasks) != 1 || decoded.Tasks[0]["end"] != nil || decoded.Tasks[0]["running"] != true {
+		t.Errorf("expected a running task without an end, got %+v", decoded.Tasks)
+	}
+
+	if err := WriteReport(&out, "xml", r); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
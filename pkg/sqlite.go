@@ -0,0 +1,476 @@
+package pkg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const DEFAULT_DB_NAME = "my-tasks.db"
+
+// sqliteMigrations[i] upgrades the database from version i to i+1. The
+// version is kept in PRAGMA user_version.
+var sqliteMigrations = []func(tx *sql.Tx) error{
+	execSQL(`
+	CREATE TABLE IF NOT EXISTS tasks (
+		id         INTEGER PRIMARY KEY,
+		project    TEXT    NOT NULL,
+		language   TEXT    NOT NULL,
+		started_at INTEGER NOT NULL,
+		ended_at   INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS tasks_started_at ON tasks (started_at);
+	CREATE INDEX IF NOT EXISTS tasks_project ON tasks (project, started_at);
+	CREATE INDEX IF NOT EXISTS tasks_language ON tasks (language, started_at);
+	`),
+	// add a stable ID to every task
+	func(tx *sql.Tx) error {
+		if _, err := tx.Exec("ALTER TABLE tasks ADD COLUMN uid TEXT"); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query("SELECT id FROM tasks")
+		if err != nil {
+			return err
+		}
+
+		var rowIDs []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			rowIDs = append(rowIDs, id)
+		}
+		rows.Close()
+
+		ids := newIDSet()
+		for _, id := range rowIDs {
+			if _, err := tx.Exec("UPDATE tasks SET uid = ? WHERE id = ?", ids.next(), id); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec("CREATE UNIQUE INDEX tasks_uid ON tasks (uid)")
+		return err
+	},
+	// tags are stored separated by spaces
+	execSQL(`
+	ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN note TEXT NOT NULL DEFAULT '';
+	`),
+	// breaks are stored as "start/end" intervals separated by spaces
+	execSQL("ALTER TABLE tasks ADD COLUMN pauses TEXT NOT NULL DEFAULT ''"),
+}
+
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+const taskColumns = "uid, project, language, started_at, ended_at, tags, note, pauses"
+
+// sqlExecutor is implemented by *sql.DB and *sql.Tx.
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// SQLiteStore keeps tasks in an embedded SQLite database. Timestamps are
+// stored as unix seconds, so range queries can use the indexes.
+type SQLiteStore struct {
+	db  *sql.DB
+	loc *time.Location
+}
+
+// OpenSQLiteStore opens (or creates) the database filename. When the database
+// is created, the tasks of the existing csv file csvFilename are imported.
+func OpenSQLiteStore(filename, csvFilename string, loc *time.Location) (*SQLiteStore, error) {
+	path, err := dataPath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	isNew := os.IsNotExist(err)
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening database %s: %w", filename, err)
+	}
+
+	s := &SQLiteStore{db: db, loc: loc}
+
+	if err := s.migrate(path, !isNew); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if isNew && csvFilename != "" {
+		if err := s.importCSV(csvFilename); err != nil {
+			db.Close()
+			return nil, err
+		}
+		if err := checkpoint(path, OpMigrate); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// migrate upgrades the database to the latest schema version. An existing
+// database is copied to the backup directory before it is changed.
+func (s *SQLiteStore) migrate(path string, existed bool) error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	latest := len(sqliteMigrations)
+	if version > latest {
+		return fmt.Errorf("%s uses schema version %d, this goalkeeper only knows up to %d, please update",
+			filepath.Base(path), version, latest)
+	}
+
+	if version == latest {
+		return nil
+	}
+
+	if existed {
+		backup, err := s.backupBeforeMigration(path, version)
+		if err != nil {
+			return err
+		}
+		log.Printf("migrating %s from schema version %d to %d, the old database is kept at %s\n",
+			filepath.Base(path), version, latest, backup)
+	}
+
+	for v := version; v < latest; v++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if err := sqliteMigrations[v](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error migrating database to schema version %d: %w", v+1, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error storing schema version %d: %w", v+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	if existed {
+		return checkpoint(path, OpMigrate)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) backupBeforeMigration(path string, version int) (string, error) {
+	backup, err := migrationBackupPath(path, version)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.db.Exec("VACUUM INTO ?", backup); err != nil {
+		return "", fmt.Errorf("error backing up %s before migration: %w", path, err)
+	}
+
+	return backup, nil
+}
+
+func (s *SQLiteStore) importCSV(csvFilename string) error {
+	// Malformed rows stay in the csv file, everything else is imported
+	tasks, err := LoadTasks(csvFilename, s.loc)
+	var skipped *SkippedRowsError
+	if err != nil && !errors.As(err, &skipped) {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error importing %s: %w", csvFilename, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range tasks {
+		if err := insertTask(tx, t); err != nil {
+			return fmt.Errorf("error importing %s: %w", t, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Load(from, to time.Time) ([]*Task, error) {
+	return s.Query(TaskFilter{From: from, To: to})
+}
+
+func (s *SQLiteStore) Query(filter TaskFilter) ([]*Task, error) {
+	where := []string{"1 = 1"}
+	args := []any{}
+
+	if filter.Project != "" {
+		where = append(where, "project = ?")
+		args = append(args, filter.Project)
+	}
+	if filter.Language != "" {
+		where = append(where, "language = ?")
+		args = append(args, filter.Language)
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "started_at >= ?")
+		args = append(args, filter.From.Unix())
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "started_at < ?")
+		args = append(args, filter.To.Unix())
+	}
+
+	rows, err := s.db.Query(
+		"SELECT "+taskColumns+" FROM tasks WHERE "+strings.Join(where, " AND ")+
+			" ORDER BY started_at, id",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*Task{}
+	for rows.Next() {
+		t, err := s.scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (s *SQLiteStore) Last() (*Task, error) {
+	row := s.db.QueryRow(
+		"SELECT " + taskColumns + " FROM tasks ORDER BY started_at DESC, id DESC LIMIT 1")
+
+	t, err := s.scanTask(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (s *SQLiteStore) Get(id string) (*Task, error) {
+	row := s.db.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE uid = ?", id)
+
+	t, err := s.scanTask(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrTaskNotFound
+	}
+	return t, err
+}
+
+func (s *SQLiteStore) Append(task *Task) error {
+	return s.Apply(Change{After: task})
+}
+
+func (s *SQLiteStore) Update(task *Task) error {
+	before, err := s.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before, After: task})
+}
+
+func (s *SQLiteStore) Delete(task *Task) error {
+	before, err := s.Get(task.ID)
+	if err != nil {
+		return err
+	}
+	return s.Apply(Change{Before: before})
+}
+
+// Apply makes all changes in a single transaction.
+func (s *SQLiteStore) Apply(changes ...Change) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range changes {
+		if err := s.applyChange(tx, c); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) applyChange(db sqlExecutor, c Change) error {
+	if c.Before != nil {
+		if err := s.checkStored(db, c.Before); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case c.Before == nil && c.After == nil:
+		return nil
+	case c.Before == nil:
+		return insertTask(db, c.After)
+	case c.After == nil:
+		res, err := db.Exec("DELETE FROM tasks WHERE uid = ?", c.Before.ID)
+		if err != nil {
+			return fmt.Errorf("error deleting %s: %w", c.Before, err)
+		}
+		return expectAffected(res, c.Before.ID)
+	default:
+		if c.After.ID != c.Before.ID {
+			return fmt.Errorf("the ID of task %s can not be changed", c.Before.ID)
+		}
+		res, err := db.Exec(
+			"UPDATE tasks SET uid = ?, project = ?, language = ?, started_at = ?, ended_at = ?, tags = ?, note = ?, pauses = ? WHERE uid = ?",
+			append(taskArgs(c.After), c.Before.ID)...,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating %s: %w", c.After, err)
+		}
+		return expectAffected(res, c.Before.ID)
+	}
+}
+
+// checkStored makes sure the stored task with the ID of before equals it.
+func (s *SQLiteStore) checkStored(db sqlExecutor, before *Task) error {
+	stored, err := s.scanTask(db.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE uid = ?", before.ID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, before.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if !stored.Equal(*before) {
+		return fmt.Errorf("%w: %s", ErrTaskChanged, before.ID)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *SQLiteStore) scanTask(row scanner) (*Task, error) {
+	var (
+		t      Task
+		start  int64
+		end    sql.NullInt64
+		tags   string
+		pauses string
+	)
+
+	if err := row.Scan(&t.ID, &t.Project, &t.Language, &start, &end, &tags, &t.Note, &pauses); err != nil {
+		return nil, err
+	}
+
+	t.Tags = ParseTags(tags)
+
+	var err error
+	if t.Pauses, err = parsePauses(pauses, s.loc); err != nil {
+		return nil, fmt.Errorf("invalid pauses of task %s: %w", t.ID, err)
+	}
+
+	t.Start = time.Unix(start, 0).In(s.loc)
+	if end.Valid {
+		t.End = time.Unix(end.Int64, 0).In(s.loc)
+	}
+
+	return &t, nil
+}
+
+func taskArgs(t *Task) []any {
+	var end sql.NullInt64
+	if t.IsFinished() {
+		end = sql.NullInt64{Int64: t.End.Unix(), Valid: true}
+	}
+	return []any{t.ID, t.Project, t.Language, t.Start.Unix(), end, strings.Join(t.Tags, " "), t.Note,
+		formatPauses(t.Pauses)}
+}
+
+// insertTask stores task, assigning it a new ID if it has none.
+func insertTask(db sqlExecutor, task *Task) error {
+	if task.ID == "" {
+		for {
+			id := randomID()
+			exists, err := idExists(db, id)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				task.ID = id
+				break
+			}
+		}
+	} else {
+		exists, err := idExists(db, task.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrTaskExists, task.ID)
+		}
+	}
+
+	_, err := db.Exec(
+		"INSERT INTO tasks ("+taskColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		taskArgs(task)...,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting %s: %w", task, err)
+	}
+	return nil
+}
+
+func idExists(db sqlExecutor, id string) (bool, error) {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM tasks WHERE uid = ?", id).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func expectAffected(res sql.Result, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	return nil
+}
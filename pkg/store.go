@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	BACKEND_CSV    = "csv"
+	BACKEND_SQLITE = "sqlite"
+)
+
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	ErrTaskExists   = errors.New("task already exists")
+	// ErrTaskChanged is returned when a stored task no longer equals the
+	// Before of a change, e.g. after the task file was edited by hand.
+	ErrTaskChanged = errors.New("task was changed since")
+)
+
+// Store persists tasks. Implementations return tasks ordered by start time.
+// Load, Query and Last may return a *SkippedRowsError together with the
+// tasks that could be read.
+type Store interface {
+	// Load returns all tasks that started in [from, to). A zero from or to
+	// leaves that side of the range open.
+	Load(from, to time.Time) ([]*Task, error)
+	// Query returns all tasks matching filter.
+	Query(filter TaskFilter) ([]*Task, error)
+	// Last returns the most recently started task or nil if there is none.
+	Last() (*Task, error)
+	// Get returns the task with the given ID or ErrTaskNotFound.
+	Get(id string) (*Task, error)
+	// Append stores a new task. Tasks without an ID are assigned a new one.
+	Append(task *Task) error
+	// Update overwrites the stored task with the same ID as task.
+	Update(task *Task) error
+	// Delete removes the stored task with the same ID as task.
+	Delete(task *Task) error
+	// Apply makes all changes at once: either all of them are stored or none.
+	Apply(changes ...Change) error
+	Close() error
+}
+
+// Change is a single modification of the stored tasks. Before is nil for a
+// new task and After is nil for a deleted one. Tasks are matched by ID and
+// the stored task has to equal Before, otherwise ErrTaskChanged is returned.
+type Change struct {
+	Before *Task `json:"before,omitempty"`
+	After  *Task `json:"after,omitempty"`
+}
+
+// Inverse returns the change that reverts c.
+func (c Change) Inverse() Change {
+	return Change{Before: c.After, After: c.Before}
+}
+
+// TaskFilter narrows down the tasks returned by Store.Query.
+// Zero values match every task.
+type TaskFilter struct {
+	Project  string
+	Language string
+	From     time.Time
+	To       time.Time
+}
+
+func (f TaskFilter) Match(t *Task) bool {
+	if f.Project != "" && t.Project != f.Project {
+		return false
+	}
+	if f.Language != "" && t.Language != f.Language {
+		return false
+	}
+	if !f.From.IsZero() && t.Start.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !t.Start.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// OpenStore opens the storage backend selected in the config section. Loaded
+// tasks have their timestamps in the configured time zone, and all changes are
+// recorded in a journal next to the data file.
+func OpenStore(config ConfigSection) (*JournaledStore, error) {
+	loc, err := config.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		store    Store
+		filename string
+	)
+
+	switch config.Backend {
+	case "", BACKEND_CSV:
+		store, filename = NewCSVStore(config.Filename, loc), config.Filename
+	case BACKEND_SQLITE:
+		if store, err = OpenSQLiteStore(config.Database, config.Filename, loc); err != nil {
+			return nil, err
+		}
+		filename = config.Database
+	default:
+		return nil, fmt.Errorf("%w: unknown backend %q, use %q or %q",
+			ErrConfigInvalid, config.Backend, BACKEND_CSV, BACKEND_SQLITE)
+	}
+
+	journal, err := JournalFor(filename)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	return NewJournaledStore(store, journal), nil
+}
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func openTestStores(t *testing.T) map[string]Store {
+	t.Helper()
+	setupHome(t)
+
+	sqlite, err := OpenSQLiteStore("tasks.db", "", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]Store{
+		BACKEND_CSV:    NewCSVStore("tasks.csv", testLocation),
+		BACKEND_SQLITE: sqlite,
+	}
+}
+
+func TestStores(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+
+			for _, task := range []*Task{
+				testTask("goalkeeper", day, time.Hour),
+				testTask("website", day.Add(2*time.Hour), time.Hour),
+				testTask("goalkeeper", day.AddDate(0, 0, 1), time.Hour),
+			} {
+				if err := store.Append(task); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tasks, err := store.Load(StartOfDay(day), StartOfDay(day).AddDate(0, 0, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 tasks on %s, got %d", day.Format(DateFormat), len(tasks))
+			}
+
+			tasks, err = store.Query(TaskFilter{Project: "goalkeeper"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 goalkeeper tasks, got %d", len(tasks))
+			}
+
+			last, err := store.Last()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !last.Start.Equal(day.AddDate(0, 0, 1)) {
+				t.Errorf("expected last task to start at %s, got %s", day.AddDate(0, 0, 1), last.Start)
+			}
+
+			if last.ID == "" {
+				t.Fatal("expected stored task to have an ID")
+			}
+
+			got, err := store.Get(last.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Start.Equal(last.Start) {
+				t.Errorf("expected task %s to start at %s, got %s", last.ID, last.Start, got.Start)
+			}
+
+			if _, err := store.Get("000000"); !errors.Is(err, ErrTaskNotFound) {
+				t.Errorf("expected ErrTaskNotFound, got %v", err)
+			}
+
+			last.Language = "Rust"
+			if err := store.Update(last); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Delete(tasks[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			tasks, err = store.Load(time.Time{}, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 tasks after delete, got %d", len(tasks))
+			}
+			if tasks[1].Language != "Rust" {
+				t.Errorf("expected updated language %q, got %q", "Rust", tasks[1].Language)
+			}
+		})
+	}
+}
+
+func TestStoreChanged(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			task := testTask("goalkeeper", start, time.Hour)
+			if err := store.Append(task); err != nil {
+				t.Fatal(err)
+			}
+
+			stale := *task
+			edited := *task
+			edited.Note = "edited in the meantime"
+			if err := store.Update(&edited); err != nil {
+				t.Fatal(err)
+			}
+
+			reverted := stale
+			reverted.Language = "Rust"
+			if err := store.Apply(Change{Before: &stale, After: &reverted}); !errors.Is(err, ErrTaskChanged) {
+				t.Errorf("expected ErrTaskChanged for a stale update, got %v", err)
+			}
+			if err := store.Apply(Change{Before: &stale}); !errors.Is(err, ErrTaskChanged) {
+				t.Errorf("expected ErrTaskChanged for a stale delete, got %v", err)
+			}
+
+			got, err := store.Get(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(edited) {
+				t.Errorf("expected the edited task to be kept, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestStoreTaskDetails(t *testing.T) {
+	for name, store := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+			task := testTask("goalkeeper", start, time.Hour)
+			task.Tags = []string{"bugfix", "review"}
+			task.Note = "fix the parser, again"
+			task.Pauses = []Pause{{Start: start.Add(10 * time.Minute), End: start.Add(20 * time.Minute)}}
+
+			if err := store.Append(task); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := store.Get(task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(*task) {
+				t.Errorf("expected %+v, got %+v", task, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package pkg
+
+import "time"
+
+// DayStatus is how a day did against the daily goal.
+type DayStatus int
+
+const (
+	// DayRest is a rest day or a day without a goal, it never breaks a streak.
+	DayRest DayStatus = iota
+	DayMet
+	DayMissed
+	// DayOpen is today while the goal is not met yet.
+	DayOpen
+)
+
+// DailyTotals adds up the durations of tasks by the day they started on in
+// loc. The keys are the starts of the days.
+func DailyTotals(tasks []*Task, loc *time.Location) map[time.Time]time.Duration {
+	totals := make(map[time.Time]time.Duration)
+	for _, t := range tasks {
+		totals[StartOfDay(t.Start.In(loc))] += t.Duration()
+	}
+	return totals
+}
+
+// Streak summarizes how often the daily goal was met.
+type Streak struct {
+	// Current is the number of work days in a row up to today on which the
+	// goal was met. Today only counts once its goal is met, but does not
+	// break the streak before the day is over.
+	Current      int
+	CurrentStart time.Time
+	// Longest is the longest streak so far, from LongestStart to LongestEnd.
+	Longest      int
+	LongestStart time.Time
+	LongestEnd   time.Time
+	// Met and Judged count the work days with a goal, excluding an open today.
+	Met    int
+	Judged int
+	// Days holds the status of every day from the first task up to today.
+	Days map[time.Time]DayStatus
+}
+
+// Rate returns the part of the judged days on which the goal was met.
+func (s Streak) Rate() float64 {
+	if s.Judged == 0 {
+		return 0
+	}
+	return float64(s.Met) / float64(s.Judged)
+}
+
+// ComputeStreak judges every day from the first of tasks up to today against
+// the daily goal returned by goal, so goals can change over time. Days that
+// are not work days of calendar are rest days.
+func ComputeStreak(tasks []*Task, calendar Calendar, goal func(day time.Time) time.Duration, today time.Time) Streak {
+	today = StartOfDay(today)
+	totals := DailyTotals(tasks, today.Location())
+	s := Streak{Days: make(map[time.Time]DayStatus)}
+
+	first := today
+	for day := range totals {
+		if day.Before(first) {
+			first = day
+		}
+	}
+
+	var (
+		run      int
+		runStart time.Time
+	)
+
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		target := goal(day)
+
+		status := DayRest
+		switch {
+		case target <= 0 || !calendar.IsWorkDay(day):
+		case totals[day] >= target:
+			status = DayMet
+		case day.Equal(today):
+			status = DayOpen
+		default:
+			status = DayMissed
+		}
+		s.Days[day] = status
+
+		switch status {
+		case DayMet:
+			s.Met++
+			s.Judged++
+			if run == 0 {
+				runStart = day
+			}
+			run++
+			if run > s.Longest {
+				s.Longest, s.LongestStart, s.LongestEnd = run, runStart, day
+			}
+		case DayMissed:
+			s.Judged++
+			run = 0
+		}
+	}
+
+	if run > 0 {
+		s.Current, s.CurrentStart = run, runStart
+	}
+
+	return s
+}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeStreak(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 9, 0, 0, 0, testLocation)
+	}
+
+	// October 2024 starts on a Tuesday
+	tasks := []*Task{
+		testTask("goalkeeper", day(1), time.Hour),
+		testTask("goalkeeper", day(2), 30*time.Minute), // missed
+		testTask("goalkeeper", day(3), time.Hour),
+		testTask("goalkeeper", day(4), 2*time.Hour),
+		// the weekend is a rest day
+		testTask("goalkeeper", day(7), time.Hour),
+		testTask("goalkeeper", day(8), 20*time.Minute),
+		testTask("goalkeeper", day(8).Add(time.Hour), 40*time.Minute),
+	}
+
+	goal := func(d time.Time) time.Duration {
+		// the goal was raised on the 8th
+		if d.Before(StartOfDay(day(8))) {
+			return time.Hour
+		}
+		return 2 * time.Hour
+	}
+
+	// today is open, it does not break the streak yet
+	s := ComputeStreak(tasks, DefaultCalendar, goal, day(8))
+	if s.Current != 3 || !s.CurrentStart.Equal(StartOfDay(day(3))) {
+		t.Errorf("expected a current streak of 3 since the 3rd, got %d since %s", s.Current, s.CurrentStart)
+	}
+	if s.Days[StartOfDay(day(8))] != DayOpen {
+		t.Errorf("expected today to be open, got %v", s.Days[StartOfDay(day(8))])
+	}
+	if s.Met != 4 || s.Judged != 5 {
+		t.Errorf("expected the goal met on 4 of 5 days, got %d of %d", s.Met, s.Judged)
+	}
+
+	// a day later the 8th is missed against the raised goal
+	s = ComputeStreak(tasks, DefaultCalendar, goal, day(9))
+	if s.Current != 0 {
+		t.Errorf("expected the streak to be broken, got %d", s.Current)
+	}
+	if s.Longest != 3 || !s.LongestEnd.Equal(StartOfDay(day(7))) {
+		t.Errorf("expected the longest streak of 3 to end on the 7th, got %d ending %s", s.Longest, s.LongestEnd)
+	}
+}
@@ -1,8 +1,10 @@
 package pkg
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -10,34 +12,111 @@ const (
 	DateTimeFormat = "2006-01-02 15:04:05"
 	TimeFormat     = "15:04"
 	DateFormat     = "2006-01-02"
+
+	// legacyDateTimeFormat is how timestamps were stored before they carried
+	// a zone offset.
+	legacyDateTimeFormat = "2006-01-02 15:04:05"
 )
 
 type Task struct {
-	Project  string
-	Language string
-	Start    time.Time
-	End      time.Time
+	// ID identifies the task, it is assigned when the task is stored.
+	ID       string    `json:"id"`
+	Project  string    `json:"project"`
+	Language string    `json:"language"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	// Tags are short labels like "bugfix", stored without a leading #.
+	Tags []string `json:"tags,omitempty"`
+	// Note is a free text about what was done.
+	Note string `json:"note,omitempty"`
+	// Pauses are the breaks during the task, they do not count towards its
+	// duration.
+	Pauses []Pause `json:"pauses,omitempty"`
 }
 
-func NewTask(project, language string) *Task {
+func NewTask(project, language string, start time.Time) *Task {
 	return &Task{
 		Project:  project,
 		Language: language,
-		Start:    time.Now(),
+		Start:    start.Truncate(time.Second),
 	}
 }
 
+// Fields returns the task as a row of the task file. Timestamps are stored in
+// RFC 3339, so they keep their zone offset.
 func (t Task) Fields() []string {
-	return []string{t.Project, t.Language, t.Start.Format("2006-01-02 15:04:05"),
-		FormatTimeOrTBD(t.End, DateTimeFormat)}
+	return []string{t.ID, t.Project, t.Language, t.Start.Format(time.RFC3339),
+		FormatTimeOrTBD(t.End, time.RFC3339), strings.Join(t.Tags, " "), t.Note,
+		formatPauses(t.Pauses)}
+}
+
+// Equal reports whether both tasks hold the same values.
+func (t Task) Equal(other Task) bool {
+	return t.ID == other.ID && t.Project == other.Project && t.Language == other.Language &&
+		t.Start.Equal(other.Start) && t.End.Equal(other.End) &&
+		slices.Equal(t.Tags, other.Tags) && t.Note == other.Note &&
+		slices.EqualFunc(t.Pauses, other.Pauses, func(a, b Pause) bool {
+			return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+		})
+}
+
+// HasTag reports whether the task is tagged with tag.
+func (t Task) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, tag)
+}
+
+// AddTags adds the tags the task does not have yet.
+func (t *Task) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+}
+
+// ParseTags turns the values of tag flags into tags. A value can hold
+// several tags separated by commas or spaces, a leading # is dropped and
+// every tag is kept once.
+func ParseTags(values ...string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			tag = strings.TrimLeft(tag, "#")
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// FormatTags returns the tags as "#bugfix #learning".
+func FormatTags(tags []string) string {
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		formatted[i] = "#" + tag
+	}
+	return strings.Join(formatted, " ")
+}
+
+// Label returns "project (language)", or only the project for a task
+// without a language.
+func (t Task) Label() string {
+	if t.Language == "" {
+		return t.Project
+	}
+	return fmt.Sprintf("%s (%s)", t.Project, t.Language)
 }
 
 func (t Task) String() string {
 	return fmt.Sprintf(
-		"Project: %q, Language: %q: Started: %s, Ended: %s",
+		"ID: %s, Project: %q, Language: %q: Started: %s, Ended: %s",
+		t.ID,
 		t.Project,
 		t.Language,
-		t.Start.Format("2006-01-02 15:04:05"),
+		t.Start.Format(DateTimeFormat),
 		FormatTimeOrTBD(t.End, DateTimeFormat),
 	)
 }
@@ -53,40 +132,91 @@ func (t Task) IsFinished() bool {
 	return !t.End.IsZero()
 }
 
-func FromFields(fields []string) *Task {
-	location, err := time.LoadLocation("Europe/Berlin")
-	if err != nil {
-		log.Fatalf("error loading time zone location: %v", err)
+// FromFields parses a row of the task file and returns its timestamps in
+// loc. Rows written before timestamps carried an offset are read as wall
+// clock time in loc. Errors are of type *MalformedRowError.
+func FromFields(fields []string, loc *time.Location) (*Task, error) {
+	if len(fields) < len(csvColumns) {
+		return nil, &MalformedRowError{
+			Field: "row",
+			Value: strings.Join(fields, ","),
+			Err:   fmt.Errorf("expected %d fields, got %d", len(csvColumns), len(fields)),
+		}
+	}
+
+	if fields[0] == "" {
+		return nil, &MalformedRowError{Field: "ID", Value: fields[0], Err: errors.New("missing ID")}
 	}
 
-	start, err := time.ParseInLocation("2006-01-02 15:04:05", fields[2], location)
+	start, err := parseTimestamp(fields[3], loc)
 	if err != nil {
-		log.Fatalf("[start time] Error parsing time (%s): %v", fields[2], err)
+		return nil, &MalformedRowError{Field: "Start", Value: fields[3], Err: err}
 	}
 
 	var end time.Time
 
-	if fields[3] == "TBD" {
-		end = time.Time{}
-	} else {
-		end, err = time.ParseInLocation("2006-01-02 15:04:05", fields[3], location)
+	if fields[4] != "TBD" {
+		end, err = parseTimestamp(fields[4], loc)
 		if err != nil {
-			log.Fatalf("[end time] Error parsing time (%s): %v", fields[3], err)
+			return nil, &MalformedRowError{Field: "End", Value: fields[4], Err: err}
 		}
 	}
 
+	pauses, err := parsePauses(fields[7], loc)
+	if err != nil {
+		return nil, &MalformedRowError{Field: "Pauses", Value: fields[7], Err: err}
+	}
+
 	return &Task{
-		Project:  fields[0],
-		Language: fields[1],
+		ID:       fields[0],
+		Project:  fields[1],
+		Language: fields[2],
 		Start:    start,
 		End:      end,
+		Tags:     ParseTags(fields[5]),
+		Note:     fields[6],
+		Pauses:   pauses,
+	}, nil
+}
+
+func parseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t.In(loc), nil
 	}
+
+	legacy, legacyErr := time.ParseInLocation(legacyDateTimeFormat, value, loc)
+	if legacyErr != nil {
+		return time.Time{}, err
+	}
+	return legacy, nil
 }
 
 func (t *Task) Finish() {
-	t.End = time.Now()
+	t.FinishAt(time.Now())
 }
 
+// FinishAt ends the task at end. A break that is still going on ends with
+// the task.
+func (t *Task) FinishAt(end time.Time) {
+	end = end.In(t.Start.Location()).Truncate(time.Second)
+	if t.IsPaused() {
+		t.Pauses[len(t.Pauses)-1].End = end
+	}
+	t.End = end
+}
+
+// Switch returns t ended at at and a new task of project and language that
+// starts at the same second. t itself is not changed.
+func (t Task) Switch(project, language string, at time.Time) (ended, next *Task) {
+	ended = &t
+	ended.Pauses = slices.Clone(t.Pauses)
+	ended.FinishAt(at)
+	return ended, NewTask(project, language, ended.End)
+}
+
+// GetTasksForDate returns the tasks that started on the day of t. Day
+// boundaries are taken from the time zone of t.
 func GetTasksForDate(tasks []*Task, t time.Time) []*Task {
 	todayTasks := []*Task{}
 	for _, task := range tasks {
@@ -97,17 +227,57 @@ func GetTasksForDate(tasks []*Task, t time.Time) []*Task {
 	return todayTasks
 }
 
+// Duration returns the time worked on the task, without its breaks.
 func (t Task) Duration() time.Duration {
-	endtime := t.End
+	return t.endOrNow().Sub(t.Start) - t.BreakDuration()
+}
+
+// endOrNow returns the end of the task, running tasks end now.
+func (t Task) endOrNow() time.Time {
 	if t.End.IsZero() {
-		endtime = time.Now()
+		return time.Now()
 	}
+	return t.End
+}
 
-	return endtime.Sub(t.Start)
+// Validate reports a task that does not start before it ends or whose
+// breaks are not within the task.
+func (t Task) Validate() error {
+	if t.IsFinished() && !t.Start.Before(t.End) {
+		return fmt.Errorf("%w: %s is not before %s", ErrInvalidRange,
+			t.Start.Format(DateTimeFormat), t.End.Format(DateTimeFormat))
+	}
+	return t.validatePauses()
+}
+
+// Overlaps reports whether t and other share any time.
+func (t Task) Overlaps(other *Task) bool {
+	return t.Start.Before(other.endOrNow()) && other.Start.Before(t.endOrNow())
+}
+
+// CheckOverlap returns an *OverlapError for the first of tasks that overlaps
+// task. The stored version of task itself, found by its ID, is ignored.
+func CheckOverlap(task *Task, tasks []*Task) error {
+	for _, other := range tasks {
+		if task.ID != "" && other.ID == task.ID {
+			continue
+		}
+		if task.Overlaps(other) {
+			return &OverlapError{Task: task, Other: other}
+		}
+	}
+	return nil
 }
 
+// SameDay reports whether t falls on the same calendar day as ref in the
+// time zone of ref.
 func SameDay(ref, t time.Time) bool {
 	nYear, nMonth, nDay := ref.Date()
-	year, month, day := t.Date()
+	year, month, day := t.In(ref.Location()).Date()
 	return nYear == year && nMonth == month && nDay == day
 }
+
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheckOverlap(t *testing.T) {
+	day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+	tasks := []*Task{
+		{ID: "aaaaaa", Start: day, End: day.Add(time.Hour)},
+		{ID: "bbbbbb", Start: day.Add(2 * time.Hour), End: day.Add(3 * time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		task    *Task
+		overlap bool
+	}{
+		{"in between", &Task{Start: day.Add(time.Hour), End: day.Add(2 * time.Hour)}, false},
+		{"inside", &Task{Start: day.Add(10 * time.Minute), End: day.Add(20 * time.Minute)}, true},
+		{"across", &Task{Start: day.Add(30 * time.Minute), End: day.Add(150 * time.Minute)}, true},
+		{"itself", &Task{ID: "aaaaaa", Start: day.Add(30 * time.Minute), End: day.Add(time.Hour)}, false},
+		{"running", &Task{Start: day.Add(150 * time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOverlap(tt.task, tasks)
+			if got := errors.Is(err, ErrOverlap); got != tt.overlap {
+				t.Errorf("expected overlap %v, got %v", tt.overlap, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	day := time.Date(2024, 10, 1, 9, 0, 0, 0, testLocation)
+
+	task := Task{Start: day, End: day.Add(-time.Minute)}
+	if err := task.Validate(); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange, got %v", err)
+	}
+
+	task = Task{Start: day}
+	if err := task.Validate(); err != nil {
+		t.Errorf("expected running task to be valid, got %v", err)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags := ParseTags("#bugfix", "learning,review", "bugfix #go", "")
+	expected := []string{"bugfix", "learning", "review", "go"}
+	if !slices.Equal(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+
+	if got := FormatTags(tags[:2]); got != "#bugfix #learning" {
+		t.Errorf("expected %q, got %q", "#bugfix #learning", got)
+	}
+}
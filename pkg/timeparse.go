@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockFormats are times of day, they refer to the reference day.
+var clockFormats = []string{"15:04", "15:04:05"}
+
+// dateTimeFormats are complete points in time.
+var dateTimeFormats = []string{
+	time.RFC3339,
+	DateTimeFormat,
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// dateFormats are calendar days.
+var dateFormats = []string{"2006-01-02", "2.1.2006"}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// offsetPattern matches relative offsets like "-3d", "+1h30m", "2h ago" and
+// "3 days ago".
+var offsetPattern = regexp.MustCompile(`^([+-]?)((?:\d+\s*[a-z]+\s*)+?)(\s+ago)?$`)
+
+// offsetPart matches a single amount and unit of an offset.
+var offsetPart = regexp.MustCompile(`(\d+)\s*([a-z]+)`)
+
+const (
+	timeHelp = "'HH:MM', 'YYYY-MM-DD HH:MM', an offset like '-15m' or '2h ago', or a day followed by a time like 'yesterday 14:30'"
+	dateHelp = "'YYYY-MM-DD', 'DD.MM.YYYY', 'today', 'yesterday', a weekday like 'monday' or 'last friday', or an offset like '-3d' or '2 weeks ago'"
+)
+
+// ParseTime parses a point in time. A bare time of day like "14:30" refers
+// to the day of ref, a day followed by a time like "yesterday 9:00" or
+// "last monday 14:30" to that day, and an offset like "-15m" or "2h ago" is
+// added to ref itself. Values without a zone offset are read in the location
+// of ref.
+func ParseTime(value string, ref time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	loc := ref.Location()
+
+	if strings.EqualFold(value, "now") {
+		return ref.Truncate(time.Second), nil
+	}
+
+	if clock, ok := parseClock(value, ref); ok {
+		return clock, nil
+	}
+
+	for _, format := range dateTimeFormats {
+		t, err := time.ParseInLocation(format, value, loc)
+		if err == nil {
+			return t.In(loc), nil
+		}
+	}
+
+	value = strings.ToLower(value)
+
+	if t, ok, err := parseOffset(value, ref); ok {
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.Truncate(time.Second), nil
+	}
+
+	// a day followed by a time of day
+	if i := strings.LastIndex(value, " "); i != -1 {
+		day, err := ParseDate(value[:i], ref)
+		if err == nil {
+			if t, ok := parseClock(strings.TrimSpace(value[i+1:]), day); ok {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse time %q, please use %s", value, timeHelp)
+}
+
+// ParseDate parses a calendar day and returns its start in the location of
+// ref. Besides dates it understands "today", "yesterday", "tomorrow", weekday
+// names, which refer to the last such day up to and including today, "last"
+// followed by a weekday, which excludes today, and offsets like "-3d",
+// "1w ago" or "2 days ago".
+func ParseDate(value string, ref time.Time) (time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	today := StartOfDay(ref)
+
+	for _, format := range dateFormats {
+		t, err := time.ParseInLocation(format, value, ref.Location())
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	switch value {
+	case "today", "now":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	name, last := strings.CutPrefix(value, "last ")
+	if weekday, ok := ParseWeekday(name); ok {
+		days := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if last && days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	if t, ok, err := parseOffset(value, ref); ok {
+		if err != nil {
+			return time.Time{}, err
+		}
+		return StartOfDay(t), nil
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse date %q, please use %s", value, dateHelp)
+}
+
+// ParseWeekday parses the English name of a weekday like "monday" or its
+// first three letters like "mon", ignoring case.
+func ParseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for full, weekday := range weekdays {
+		if name == full || (len(name) == 3 && strings.HasPrefix(full, name)) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time of day on the day of ref.
+func parseClock(value string, ref time.Time) (time.Time, bool) {
+	for _, format := range clockFormats {
+		clock, err := time.ParseInLocation(format, value, ref.Location())
+		if err == nil {
+			year, month, day := ref.Date()
+			return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, ref.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseOffset adds a relative offset to ref. Offsets need a sign or a
+// trailing "ago", which counts backwards. Days and weeks follow the calendar,
+// so "-1d" is the same time yesterday even across daylight saving changes.
+// ok reports whether value looks like an offset at all.
+func parseOffset(value string, ref time.Time) (t time.Time, ok bool, err error) {
+	m := offsetPattern.FindStringSubmatch(value)
+	if m == nil || (m[1] == "" && m[3] == "") {
+		return time.Time{}, false, nil
+	}
+
+	sign := 1
+	if m[1] == "-" {
+		sign = -1
+	}
+	if m[3] != "" {
+		if m[1] != "" {
+			return time.Time{}, true, fmt.Errorf("offset %q has both a sign and 'ago'", value)
+		}
+		sign = -1
+	}
+
+	var (
+		days     int
+		duration time.Duration
+	)
+
+	for _, part := range offsetPart.FindAllStringSubmatch(m[2], -1) {
+		n, err := strconv.Atoi(part[1])
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("invalid offset %q: %w", value, err)
+		}
+
+		switch part[2] {
+		case "s", "sec", "secs", "second", "seconds":
+			duration += time.Duration(n) * time.Second
+		case "m", "min", "mins", "minute", "minutes":
+			duration += time.Duration(n) * time.Minute
+		case "h", "hr", "hrs", "hour", "hours":
+			duration += time.Duration(n) * time.Hour
+		case "d", "day", "days":
+			days += n
+		case "w", "week", "weeks":
+			days += 7 * n
+		default:
+			return time.Time{}, true, fmt.Errorf("unknown unit %q in offset %q, use s, m, h, d or w", part[2], value)
+		}
+	}
+
+	return ref.AddDate(0, 0, sign*days).Add(time.Duration(sign) * duration), true, nil
+}
@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	ref := time.Date(2024, 10, 1, 12, 30, 0, 0, testLocation)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"9:15", time.Date(2024, 10, 1, 9, 15, 0, 0, testLocation)},
+		{"17:30:15", time.Date(2024, 10, 1, 17, 30, 15, 0, testLocation)},
+		{"2024-09-30 08:00", time.Date(2024, 9, 30, 8, 0, 0, 0, testLocation)},
+		{"2024-09-30T08:00:00Z", time.Date(2024, 9, 30, 10, 0, 0, 0, testLocation)},
+		{"-15m", time.Date(2024, 10, 1, 12, 15, 0, 0, testLocation)},
+		{"+1h30m", time.Date(2024, 10, 1, 14, 0, 0, 0, testLocation)},
+		{"2h ago", time.Date(2024, 10, 1, 10, 30, 0, 0, testLocation)},
+		{"1 hour 15 minutes ago", time.Date(2024, 10, 1, 11, 15, 0, 0, testLocation)},
+		{"-1d", time.Date(2024, 9, 30, 12, 30, 0, 0, testLocation)},
+		{"now", ref},
+		{"yesterday 14:30", time.Date(2024, 9, 30, 14, 30, 0, 0, testLocation)},
+		{"Last Monday 9:00", time.Date(2024, 9, 30, 9, 0, 0, 0, testLocation)},
+		{"2024-09-01 7:05", time.Date(2024, 9, 1, 7, 5, 0, 0, testLocation)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, ref)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %s, expected %s", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "tomorrow-ish", "-15", "25:00", "-2y", "-2h ago", "yesterday 25:00"} {
+		if _, err := ParseTime(value, ref); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	// a Tuesday
+	ref := time.Date(2024, 10, 1, 12, 30, 0, 0, testLocation)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, testLocation)
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-09-30", day(9, 30)},
+		{"30.9.2024", day(9, 30)},
+		{"30.09.2024", day(9, 30)},
+		{"today", day(10, 1)},
+		{"Yesterday", day(9, 30)},
+		{"tomorrow", day(10, 2)},
+		{"monday", day(9, 30)},
+		{"tuesday", day(10, 1)},
+		{"last tuesday", day(9, 24)},
+		{"last sunday", day(9, 29)},
+		{"-3d", day(9, 28)},
+		{"2 weeks ago", day(9, 17)},
+		{"1w ago", day(9, 24)},
+		{"13h ago", day(9, 30)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.value, ref)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %s, expected %s", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "someday", "31.2.2024", "last", "3d"} {
+		if _, err := ParseDate(value, ref); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package pkg
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -14,78 +21,386 @@ func init() {
 }
 
 const (
-	DEFAULT_CONFIG_NAME = "config.toml"
-	DEFAULT_CSV_NAME    = "my-tasks.csv"
-	DEFAULT_PATH        = ".goalkeeper"
+	DEFAULT_CONFIG_NAME     = "config.toml"
+	DEFAULT_CSV_NAME        = "my-tasks.csv"
+	DEFAULT_QUARANTINE_NAME = "quarantine.csv"
+	DEFAULT_PATH            = ".goalkeeper"
 )
 
 type ConfigSection struct {
 	Filename string `toml:"name"`
+	// Backend selects where tasks are stored, either "csv" or "sqlite".
+	Backend  string `toml:"backend"`
+	Database string `toml:"database"`
+	// Timezone is an IANA zone name like "Europe/Berlin" used for
+	// displaying times and for day boundaries. Empty means the system zone.
+	Timezone string `toml:"timezone"`
+	// WeekStart is the weekday weeks begin on, "monday" if empty.
+	WeekStart string `toml:"week_start"`
+	// WorkDays are the weekdays that count towards goals and streaks,
+	// Monday to Friday if empty.
+	WorkDays []string `toml:"work_days"`
 }
 
+// Location returns the configured time zone.
+func (c ConfigSection) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown config.timezone %q: %v", ErrConfigInvalid, c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Calendar returns the configured working week.
+func (c ConfigSection) Calendar() (Calendar, error) {
+	calendar := DefaultCalendar
+
+	if c.WeekStart != "" {
+		weekday, ok := ParseWeekday(c.WeekStart)
+		if !ok {
+			return Calendar{}, fmt.Errorf("%w: unknown config.week_start %q, use a weekday like \"monday\"",
+				ErrConfigInvalid, c.WeekStart)
+		}
+		calendar.WeekStart = weekday
+	}
+
+	if len(c.WorkDays) > 0 {
+		calendar.WorkDays = [7]bool{}
+		for _, name := range c.WorkDays {
+			weekday, ok := ParseWeekday(name)
+			if !ok {
+				return Calendar{}, fmt.Errorf("%w: unknown weekday %q in config.work_days", ErrConfigInvalid, name)
+			}
+			calendar.WorkDays[weekday] = true
+		}
+	}
+
+	return calendar, nil
+}
+
+// GoalsSection holds the time goals in minutes, 0 means no goal.
 type GoalsSection struct {
-	Daily int `toml:"daily"`
+	Daily   int `toml:"daily"`
+	Weekly  int `toml:"weekly"`
+	Monthly int `toml:"monthly"`
+	Yearly  int `toml:"yearly"`
+	// Projects and Languages hold budgets by project or language name.
+	Projects  map[string]Budget `toml:"projects,omitempty"`
+	Languages map[string]Budget `toml:"languages,omitempty"`
+	// History holds changes of the goals above, so past days keep being
+	// judged against the goals that were in force back then. The goals
+	// above apply before the first change.
+	History []GoalChange `toml:"history,omitempty"`
+	// Milestones are targets for the total time, see Milestone.
+	Milestones []Milestone `toml:"milestones,omitempty"`
+}
+
+// GoalChange sets new goals from a day on. Goals that are left out keep
+// their previous value.
+type GoalChange struct {
+	// From is the first day the goals are in force, as "YYYY-MM-DD".
+	From    string `toml:"from"`
+	Daily   *int   `toml:"daily,omitempty"`
+	Weekly  *int   `toml:"weekly,omitempty"`
+	Monthly *int   `toml:"monthly,omitempty"`
+	Yearly  *int   `toml:"yearly,omitempty"`
+}
+
+// ProjectSection holds the defaults of a project, set in [projects.<name>].
+type ProjectSection struct {
+	// Language is used when a task of the project is recorded without one.
+	Language string `toml:"language,omitempty"`
+	// Tags are added to every new task of the project.
+	Tags []string `toml:"tags,omitempty"`
 }
 
 type TomlDocument struct {
 	ConfigSection ConfigSection `toml:"config"`
 	GoalsSection  GoalsSection  `toml:"goals"`
+	// Projects holds the defaults by project name.
+	Projects map[string]ProjectSection `toml:"projects,omitempty"`
 }
 
 func DefaultTomlConfig() TomlDocument {
 	return TomlDocument{
 		ConfigSection: ConfigSection{
-			Filename: DEFAULT_CSV_NAME,
+			Filename:  DEFAULT_CSV_NAME,
+			Backend:   BACKEND_CSV,
+			Database:  DEFAULT_DB_NAME,
+			WeekStart: "monday",
+			WorkDays:  []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
 		},
 	}
 }
 
 func LoadTomlConfig() (TomlDocument, error) {
 	var tomlDoc TomlDocument
-	path := filepath.Join(DefaultPath(), DEFAULT_CONFIG_NAME)
-	_, err := toml.DecodeFile(path, &tomlDoc)
+
+	path, err := dataPath(DEFAULT_CONFIG_NAME)
 	if err != nil {
-		if os.IsNotExist(err) {
+		return TomlDocument{}, err
+	}
+
+	_, err = toml.DecodeFile(path, &tomlDoc)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
 			return TomlDocument{}, err
 		}
-		log.Fatalf("could not parse config.toml: %v", err)
+		return TomlDocument{}, fmt.Errorf("%w: %s: %v", ErrConfigInvalid, DEFAULT_CONFIG_NAME, err)
+	}
+
+	if err := tomlDoc.Validate(); err != nil {
+		return TomlDocument{}, err
 	}
 
 	return tomlDoc, nil
 }
 
-func CreateProjectDir(path string) error {
-	err := os.Mkdir(path, 0744)
-	if err != nil {
-		if os.IsExist(err) {
-			log.Fatal("This should never happen. This Method should only be called if the project irectory does not exist yet and needs to be created!")
+// Validate reports settings that can not be used. The returned error wraps
+// ErrConfigInvalid.
+func (doc TomlDocument) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s: %s", ErrConfigInvalid, DEFAULT_CONFIG_NAME, fmt.Sprintf(format, args...))
+	}
+
+	config := doc.ConfigSection
+	if config.Filename == "" {
+		return invalid("config.name must not be empty")
+	}
+
+	switch config.Backend {
+	case "", BACKEND_CSV:
+	case BACKEND_SQLITE:
+		if config.Database == "" {
+			return invalid("config.database must not be empty when using the %s backend", BACKEND_SQLITE)
+		}
+	default:
+		return invalid("unknown config.backend %q, use %q or %q", config.Backend, BACKEND_CSV, BACKEND_SQLITE)
+	}
+
+	if _, err := config.Location(); err != nil {
+		return err
+	}
+
+	if _, err := config.Calendar(); err != nil {
+		return err
+	}
+
+	for name, minutes := range map[string]int{
+		"daily":   doc.GoalsSection.Daily,
+		"weekly":  doc.GoalsSection.Weekly,
+		"monthly": doc.GoalsSection.Monthly,
+		"yearly":  doc.GoalsSection.Yearly,
+	} {
+		if minutes < 0 {
+			return invalid("goals.%s must not be negative", name)
+		}
+	}
+
+	for i, change := range doc.GoalsSection.History {
+		if _, err := change.day(time.UTC); err != nil {
+			return invalid("goals.history[%d]: %v", i, err)
+		}
+		for name, minutes := range map[string]*int{
+			"daily":   change.Daily,
+			"weekly":  change.Weekly,
+			"monthly": change.Monthly,
+			"yearly":  change.Yearly,
+		} {
+			if minutes != nil && *minutes < 0 {
+				return invalid("goals.history[%d].%s must not be negative", i, name)
+			}
+		}
+	}
+
+	for i, m := range doc.GoalsSection.Milestones {
+		if err := m.validate(); err != nil {
+			return invalid("goals.milestones[%d]: %v", i, err)
+		}
+	}
+
+	for section, budgets := range map[string]map[string]Budget{
+		"projects":  doc.GoalsSection.Projects,
+		"languages": doc.GoalsSection.Languages,
+	} {
+		for name, budget := range budgets {
+			if err := budget.validate(); err != nil {
+				return invalid("goals.%s.%s: %v", section, name, err)
+			}
+		}
+	}
+
+	for name, project := range doc.Projects {
+		for _, tag := range project.Tags {
+			if tags := ParseTags(tag); len(tags) != 1 || tags[0] != strings.TrimLeft(tag, "#") {
+				return invalid("projects.%s.tags: %q is not a single tag", name, tag)
+			}
 		}
 	}
-	return err
+
+	return nil
+}
+
+// CreateProjectDir creates the directory holding the config and task files.
+func CreateProjectDir(path string) error {
+	if err := os.MkdirAll(path, 0744); err != nil {
+		return fmt.Errorf("error creating project directory %s: %w", path, err)
+	}
+	return nil
 }
 
 func CreateTomlFile(config TomlDocument) error {
-	path := filepath.Join(DefaultPath(), DEFAULT_CONFIG_NAME)
+	path, err := dataPath(DEFAULT_CONFIG_NAME)
+	if err != nil {
+		return err
+	}
+
 	f, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("error creating config.toml: %v", err)
+		return fmt.Errorf("error creating config.toml: %w", err)
 	}
 	defer f.Close()
 
 	encoder := toml.NewEncoder(f)
 	if err := encoder.Encode(config); err != nil {
-		return fmt.Errorf("error encoding toml config (%v): %v", config, err)
+		return fmt.Errorf("error encoding toml config (%v): %w", config, err)
 	}
 
 	log.Printf("created default toml config (%s)\n", DEFAULT_CONFIG_NAME)
 	return nil
 }
 
-func DefaultPath() string {
+// historyHeader starts an entry of goals.history in the config file.
+const historyHeader = "[[goals.history]]"
+
+// SaveGoal records in the config file that goal is minutes from day on, see
+// GoalsSection.SetGoal. Only the goals.history entry of day is written, the
+// rest of the file is kept as it is, comments included.
+func SaveGoal(goal string, minutes int, day time.Time) error {
+	path, err := dataPath(DEFAULT_CONFIG_NAME)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", DEFAULT_CONFIG_NAME, err)
+	}
+
+	var want TomlDocument
+	if _, err := toml.Decode(string(data), &want); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrConfigInvalid, DEFAULT_CONFIG_NAME, err)
+	}
+	if err := want.GoalsSection.SetGoal(goal, minutes, day); err != nil {
+		return err
+	}
+
+	content := setHistoryGoal(string(data), day.Format(DateFormat), goal, minutes)
+
+	// the file is only changed if it reads back as expected, which fails
+	// e.g. for a goals.history written as an inline array
+	var got TomlDocument
+	if _, err := toml.Decode(content, &got); err != nil || !equalHistory(got.GoalsSection.History, want.GoalsSection.History) {
+		return fmt.Errorf("could not add the change to goals.history in %s, please edit it by hand", DEFAULT_CONFIG_NAME)
+	}
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+}
+
+// setHistoryGoal sets goal to minutes in the goals.history entry of the day
+// from in the config file content. Without such an entry one is added
+// before the first entry of a later day, or at the end.
+func setHistoryGoal(content, from, goal string, minutes int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	value := fmt.Sprintf("%s = %d", goal, minutes)
+
+	fromKey := regexp.MustCompile(`^\s*from\s*=\s*"([^"]*)"`)
+	goalKey := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(goal) + `\s*=`)
+
+	insertAt := len(lines)
+	for start := 0; start < len(lines); start++ {
+		if strings.TrimSpace(lines[start]) != historyHeader {
+			continue
+		}
+
+		end := start + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "[") {
+			end++
+		}
+
+		fromLine := -1
+		for i := start + 1; i < end; i++ {
+			if m := fromKey.FindStringSubmatch(lines[i]); m != nil {
+				if m[1] > from && insertAt == len(lines) {
+					insertAt = start
+				}
+				if m[1] == from {
+					fromLine = i
+				}
+			}
+		}
+
+		if fromLine == -1 {
+			continue
+		}
+
+		indent := lines[fromLine][:len(lines[fromLine])-len(strings.TrimLeft(lines[fromLine], " \t"))]
+		for i := start + 1; i < end; i++ {
+			if goalKey.MatchString(lines[i]) {
+				lines[i] = indent + value
+				return strings.Join(lines, "\n") + "\n"
+			}
+		}
+		lines = slices.Insert(lines, fromLine+1, indent+value)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	entry := []string{historyHeader, fmt.Sprintf("from = %q", from), value, ""}
+	if insertAt == len(lines) {
+		entry = append([]string{""}, entry[:3]...)
+	}
+	lines = slices.Insert(lines, insertAt, entry...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// equalHistory reports whether a and b hold the same changes, in any order.
+func equalHistory(a, b []GoalChange) bool {
+	sorted := func(changes []GoalChange) []GoalChange {
+		changes = slices.Clone(changes)
+		slices.SortStableFunc(changes, func(a, b GoalChange) int { return strings.Compare(a.From, b.From) })
+		return changes
+	}
+	equal := func(a, b *int) bool {
+		return (a == nil) == (b == nil) && (a == nil || *a == *b)
+	}
+
+	return slices.EqualFunc(sorted(a), sorted(b), func(a, b GoalChange) bool {
+		return a.From == b.From && equal(a.Daily, b.Daily) && equal(a.Weekly, b.Weekly) &&
+			equal(a.Monthly, b.Monthly) && equal(a.Yearly, b.Yearly)
+	})
+}
+
+func DefaultPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		log.Fatal(err)
+		return "", fmt.Errorf("error finding home directory: %w", err)
 	}
 
-	return filepath.Join(home, DEFAULT_PATH)
+	return filepath.Join(home, DEFAULT_PATH), nil
+}
+
+// dataPath returns the path of filename inside the project directory.
+func dataPath(filename string) (string, error) {
+	dir, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filename), nil
 }
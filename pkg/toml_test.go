@@ -1,8 +1,13 @@
 package pkg
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -36,3 +41,130 @@ func TestTomlConfig(t *testing.T) {
 		t.Errorf("expected %d, got %d", daily, config.GoalsSection.Daily)
 	}
 }
+
+func TestTomlProjects(t *testing.T) {
+	input := `[config]
+	name = "my-tasks.csv"
+
+	[projects.goalkeeper]
+	language = "Go"
+	tags = ["#cli", "oss"]
+
+	[projects.meetings]
+	tags = ["work"]`
+
+	var config TomlDocument
+	if _, err := toml.Decode(input, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Projects["goalkeeper"].Language != "Go" || len(config.Projects["goalkeeper"].Tags) != 2 {
+		t.Errorf("expected the defaults of goalkeeper, got %+v", config.Projects["goalkeeper"])
+	}
+	if config.Projects["meetings"].Language != "" {
+		t.Errorf("expected meetings without a language, got %q", config.Projects["meetings"].Language)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+
+	config.Projects["meetings"] = ProjectSection{Tags: []string{"two tags"}}
+	if err := config.Validate(); !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("expected ErrConfigInvalid for a tag with a space, got %v", err)
+	}
+}
+
+func TestSaveGoalKeepsConfig(t *testing.T) {
+	setupHome(t)
+
+	input := `# my goalkeeper config
+[config]
+name = "my-tasks.csv" # the task file
+
+[goals]
+daily = 60 # one hour a day
+
+[goals.projects.rewrite]
+weekly = { max = 600 }
+
+# raised for the exam
+[[goals.history]]
+from = "2024-10-07"
+daily = 120
+
+[projects.goalkeeper]
+language = "Go"
+`
+	dir, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, DEFAULT_CONFIG_NAME)
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 0, 0, 0, 0, testLocation)
+	}
+	for _, change := range []struct {
+		goal    string
+		minutes int
+		day     time.Time
+	}{
+		{GOAL_DAILY, 90, day(7)},
+		{GOAL_WEEKLY, 600, day(7)},
+		{GOAL_DAILY, 30, day(1)},
+		{GOAL_DAILY, 180, day(14)},
+	} {
+		if err := SaveGoal(change.goal, change.minutes, change.day); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(data)
+
+	for _, line := range strings.Split(input, "\n") {
+		if line != "daily = 120" && !strings.Contains(output, line) {
+			t.Errorf("expected %q to be kept, got:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "min = 0") {
+		t.Errorf("expected the budget to be left alone, got:\n%s", output)
+	}
+
+	doc, err := LoadTomlConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var froms []string
+	for _, c := range doc.GoalsSection.History {
+		froms = append(froms, c.From)
+	}
+	if strings.Join(froms, " ") != "2024-10-01 2024-10-07 2024-10-14" {
+		t.Errorf("expected the changes in order, got %v", froms)
+	}
+	if goals := doc.GoalsSection.At(day(8)); goals.Daily != 90 || goals.Weekly != 600 {
+		t.Errorf("expected daily 90 and weekly 600 from 2024-10-07, got %d and %d", goals.Daily, goals.Weekly)
+	}
+	if budget := doc.GoalsSection.Projects["rewrite"]; budget.Weekly.Max != 600 {
+		t.Errorf("expected the budget of rewrite to be kept, got %+v", budget)
+	}
+}
+
+func TestBudgetOmitsEmptyLimits(t *testing.T) {
+	var doc TomlDocument
+	doc.GoalsSection.Projects = map[string]Budget{"rewrite": {Weekly: Limit{Max: 600}}}
+
+	var out strings.Builder
+	if err := toml.NewEncoder(&out).Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "rewrite.daily") || strings.Contains(out.String(), "min") {
+		t.Errorf("expected only the weekly max, got:\n%s", out.String())
+	}
+}